@@ -0,0 +1,302 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrCircuitOpen : matched with errors.Is when a call is rejected by an open circuit breaker.
+var ErrCircuitOpen = errors.New("saidp: circuit open")
+
+// CircuitState : state of the circuit breaker of an endpoint group.
+type CircuitState int
+
+const (
+	// CircuitClosed : calls flow normally and failures are counted.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen : calls fail fast with ErrCircuitOpen until the open timeout has elapsed.
+	CircuitOpen
+	// CircuitHalfOpen : a limited number of probe calls are let through to test the IdP.
+	CircuitHalfOpen
+)
+
+// String :
+//	Implements fmt.Stringer.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// BreakerSettings :
+//	Thresholds of the circuit of one endpoint group.
+// Fields:
+//	FailureThreshold: consecutive failures (transport errors and 5xx responses) opening the circuit.
+//	OpenTimeout: time the circuit stays open before probe calls are let through.
+//	HalfOpenProbes: number of concurrent probe calls allowed while half-open; 1 when zero.
+type BreakerSettings struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenProbes   int
+}
+
+// CircuitBreaker :
+//	Configuration of the circuit breaker used by Do/DoContext to fail fast while the IdP is down, instead of
+//	waiting for a timeout on every call. Circuits are kept per endpoint group.
+// Fields:
+//	Default: settings of the groups not listed in Groups.
+//	Groups: settings per endpoint group, e.g. "auth" or "users".
+//	GroupOf: maps a request to its endpoint group. When nil, EndpointGroup of the request path is used.
+//	OnStateChange: called after a circuit changes state, e.g. to switch a login page to a degraded flow.
+//	It must not block.
+type CircuitBreaker struct {
+	Default       BreakerSettings
+	Groups        map[string]BreakerSettings
+	GroupOf       func(req *http.Request) string
+	OnStateChange func(group string, from CircuitState, to CircuitState)
+}
+
+// CircuitOpenError :
+//	Error returned when a call is rejected by an open circuit. It matches ErrCircuitOpen with errors.Is.
+// Fields:
+//	Group: the endpoint group whose circuit is open.
+//	RetryAfter: time left before probe calls are let through.
+type CircuitOpenError struct {
+	Group      string
+	RetryAfter time.Duration
+}
+
+// Error :
+//	Implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v: endpoint group %q, retry after %v", ErrCircuitOpen, e.Group, e.RetryAfter)
+}
+
+// Unwrap :
+//	Returns ErrCircuitOpen so errors.Is can be used.
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// breaker :
+//	non-exportable circuit breaker state shared by all copies of a client.
+type breaker struct {
+	mu       sync.Mutex
+	config   CircuitBreaker
+	circuits map[string]*circuit
+}
+
+// circuit :
+//	non-exportable state of one endpoint group.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// DefaultCircuitBreaker :
+//	Helper function returning a breaker opening a circuit after 5 consecutive failures for 30 seconds, then
+//	letting a single probe call through.
+// Returns:
+//	CircuitBreaker: a new configuration that can be adjusted before use.
+func DefaultCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		Default: BreakerSettings{FailureThreshold: 5, OpenTimeout: 30 * time.Second, HalfOpenProbes: 1},
+	}
+}
+
+// WithCircuitBreaker :
+//	Option to enable the circuit breaker on every call made by the client. Calls rejected by an open circuit
+//	return a *CircuitOpenError without reaching the network, and are not retried.
+// Parameters:
+//	[Required] cb: the breaker configuration, see DefaultCircuitBreaker.
+func WithCircuitBreaker(cb *CircuitBreaker) ClientOption {
+	return func(c *Client) error {
+		if cb == nil {
+			return errors.New("A non-nil circuit breaker is required")
+		}
+		settings := []BreakerSettings{cb.Default}
+		for _, s := range cb.Groups {
+			settings = append(settings, s)
+		}
+		for _, s := range settings {
+			if s.FailureThreshold < 1 || s.OpenTimeout <= 0 || s.HalfOpenProbes < 0 {
+				return errors.New("Circuit breaker settings require a positive failure threshold and open timeout")
+			}
+		}
+		c.breaker = &breaker{config: *cb, circuits: make(map[string]*circuit)}
+		return nil
+	}
+}
+
+// EndpointGroup :
+//	Helper function returning the default circuit breaker group of an endpoint: the API resource following
+//	/api/v1, e.g. "users" for /api/v1/users/jsmith/factors or "auth" for /api/v1/auth.
+// Parameters:
+//	[Required] endpoint: the api endpoint after the realm, or a request path including the realm.
+// Returns:
+//	string: the endpoint group.
+func EndpointGroup(endpoint string) string {
+	if !strings.HasPrefix(endpoint, "/api/") {
+		_, endpoint = splitRealm(endpoint)
+	}
+	segments := strings.Split(strings.Trim(endpoint, "/"), "/")
+	if len(segments) >= 3 {
+		return segments[2]
+	}
+	return endpoint
+}
+
+// CircuitState :
+//	Function returning the state of the circuit of an endpoint group. Clients without a circuit breaker are
+//	always closed.
+// Parameters:
+//	[Required] group: the endpoint group.
+// Returns:
+//	CircuitState: the current state.
+func (c Client) CircuitState(group string) CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	if cir, ok := c.breaker.circuits[group]; ok {
+		if cir.state == CircuitOpen && !c.now().Before(cir.openedAt.Add(c.breaker.settings(group).OpenTimeout)) {
+			return CircuitHalfOpen
+		}
+		return cir.state
+	}
+	return CircuitClosed
+}
+
+// allow :
+//	non-exportable helper admitting an attempt through the circuit of its group. The returned function must be
+//	called with the outcome of the attempt.
+func (b *breaker) allow(req *http.Request, now func() time.Time) (func(ctx context.Context, resp *http.Response, err error), error) {
+	if b == nil {
+		return func(context.Context, *http.Response, error) {}, nil
+	}
+	group := EndpointGroup(req.URL.EscapedPath())
+	if b.config.GroupOf != nil {
+		group = b.config.GroupOf(req)
+	}
+	settings := b.settings(group)
+
+	b.mu.Lock()
+	cir, ok := b.circuits[group]
+	if !ok {
+		cir = new(circuit)
+		b.circuits[group] = cir
+	}
+	from := cir.state
+	if cir.state == CircuitOpen {
+		reopenAt := cir.openedAt.Add(settings.OpenTimeout)
+		if wait := reopenAt.Sub(now()); wait > 0 {
+			b.mu.Unlock()
+			return nil, &CircuitOpenError{Group: group, RetryAfter: wait}
+		}
+		cir.state = CircuitHalfOpen
+		cir.probes = 0
+	}
+	if cir.state == CircuitHalfOpen {
+		probes := settings.HalfOpenProbes
+		if probes == 0 {
+			probes = 1
+		}
+		if cir.probes >= probes {
+			b.mu.Unlock()
+			b.notify(group, from, cir.state)
+			return nil, &CircuitOpenError{Group: group}
+		}
+		cir.probes++
+	}
+	to := cir.state
+	b.mu.Unlock()
+	b.notify(group, from, to)
+
+	return func(ctx context.Context, resp *http.Response, err error) {
+		b.mu.Lock()
+		from := cir.state
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// A canceled call says nothing about the IdP; only release the probe slot.
+			if cir.state == CircuitHalfOpen && cir.probes > 0 {
+				cir.probes--
+			}
+		case err == nil && resp.StatusCode < http.StatusInternalServerError:
+			cir.state = CircuitClosed
+			cir.failures = 0
+		case cir.state == CircuitHalfOpen:
+			cir.state = CircuitOpen
+			cir.openedAt = now()
+		default:
+			cir.failures++
+			if cir.failures >= settings.FailureThreshold {
+				cir.state = CircuitOpen
+				cir.openedAt = now()
+			}
+		}
+		if cir.state == CircuitOpen {
+			cir.failures = 0
+		}
+		to := cir.state
+		b.mu.Unlock()
+		b.notify(group, from, to)
+	}, nil
+}
+
+// settings :
+//	non-exportable helper returning the settings of an endpoint group.
+func (b *breaker) settings(group string) BreakerSettings {
+	if s, ok := b.config.Groups[group]; ok {
+		return s
+	}
+	return b.config.Default
+}
+
+// notify :
+//	non-exportable helper calling OnStateChange when the state of a circuit changed.
+func (b *breaker) notify(group string, from CircuitState, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(group, from, to)
+	}
+}
@@ -0,0 +1,125 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestCircuitBreaker_Unit(t *testing.T) {
+	var hits int32
+	var down atomic.Bool
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	now := time.Now()
+	var mu sync.Mutex
+	var transitions []string
+	cb := DefaultCircuitBreaker()
+	cb.Groups = map[string]BreakerSettings{"users": {FailureThreshold: 2, OpenTimeout: time.Minute}}
+	cb.OnStateChange = func(group string, from CircuitState, to CircuitState) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, group+":"+from.String()+"->"+to.String())
+	}
+	client := newTestClient(t, server, WithCircuitBreaker(cb), WithClock(func() time.Time { return now }))
+	call := func() error {
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call(); !errors.Is(err, ErrServer) {
+			t.Fatalf("expected ErrServer, got %v", err)
+		}
+	}
+	if state := client.CircuitState("users"); state != CircuitOpen {
+		t.Fatalf("expected the circuit to be open, got %v", state)
+	}
+	err := call()
+	var openErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) || openErr.Group != "users" || openErr.RetryAfter != time.Minute {
+		t.Fatalf("expected a CircuitOpenError, got %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected open circuits to fail fast, got %d calls", n)
+	}
+	if state := client.CircuitState("auth"); state != CircuitClosed {
+		t.Errorf("expected other groups to stay closed, got %v", state)
+	}
+
+	now = now.Add(time.Minute)
+	if err := call(); !errors.Is(err, ErrServer) {
+		t.Fatalf("expected the half-open probe to reach the server, got %v", err)
+	}
+	if state := client.CircuitState("users"); state != CircuitOpen {
+		t.Fatalf("expected a failed probe to open the circuit again, got %v", state)
+	}
+	now = now.Add(time.Minute)
+	down.Store(false)
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if state := client.CircuitState("users"); state != CircuitClosed {
+		t.Errorf("expected a successful probe to close the circuit, got %v", state)
+	}
+	want := []string{
+		"users:closed->open", "users:open->half-open", "users:half-open->open",
+		"users:open->half-open", "users:half-open->closed",
+	}
+	if strings.Join(transitions, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected transitions %v", transitions)
+	}
+	if err := WithCircuitBreaker(&CircuitBreaker{})(client); err == nil {
+		t.Error("expected an invalid configuration to fail")
+	}
+	if group := EndpointGroup("/secureauth1/api/v1/auth/abc"); group != "auth" {
+		t.Errorf("unexpected group %q", group)
+	}
+}
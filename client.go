@@ -2,20 +2,20 @@ package saidp_sdk_go
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"encoding/json"
-
 	validators "github.com/secureauthcorp/saidp-sdk-go/utilities/validators"
 )
 
@@ -47,7 +47,7 @@ import (
  */
 
 var (
-	list                = []string{http.MethodGet, http.MethodPost, http.MethodPut}
+	list                = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch}
 	hdrContentTypeKey   = http.CanonicalHeaderKey("Content-Type")
 	hdrAcceptKey        = http.CanonicalHeaderKey("Accept")
 	hdrDateKey          = http.CanonicalHeaderKey("Date")
@@ -65,14 +65,17 @@ type Client struct {
 	Realm                string
 	SSL                  bool
 	BypassCertValidation bool
-}
-
-//HTTPError : struct to hold error message status for proper handling.
-type HTTPError struct {
-	Status     string `json:"status,omitempty"`
-	Message    string `json:"message,omitempty"`
-	Code       int    `json:",omitempty"`
-	StatusText string `json:",omitempty"`
+	httpClient           *http.Client
+	retry                *RetryPolicy
+	limiter              *limiter
+	verifySignatures     bool
+	maxSkew              time.Duration
+	middleware           []Middleware
+	callMiddleware       []Middleware
+	clock                func() time.Time
+	keys                 *keyRing
+	breaker              *breaker
+	hosts                *hostPool
 }
 
 // BuildGetRequest :
@@ -84,25 +87,7 @@ type HTTPError struct {
 //	http.Request: Http Request struct that can be used via Http Client to make the request.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) BuildGetRequest(endpoint string) (*http.Request, error) {
-	url, err := buildEndpointURL(c.SSL, c.Host, c.Port, c.Realm, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	sig, err := c.Sign(http.MethodGet, endpoint, "")
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set(hdrAcceptKey, jsonContentType)
-	req.Header.Set(hdrContentTypeKey, jsonContentType)
-	ts := getGMTTimestamp()
-	req.Header.Set(hdrDateKey, ts)
-	req.Header.Set(hdrSADateKey, ts)
-	req.Header.Set(hdrAuthorizationKey, sig)
-	return req, nil
+	return c.buildSignedRequest(http.MethodGet, endpoint, "")
 }
 
 // BuildPostRequest :
@@ -115,24 +100,7 @@ func (c Client) BuildGetRequest(endpoint string) (*http.Request, error) {
 //	http.Request: Http Request struct that can be used via Http Client to make the request.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) BuildPostRequest(endpoint string, content string) (*http.Request, error) {
-	url, err := buildEndpointURL(c.SSL, c.Host, c.Port, c.Realm, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	jsonContent := []byte(content)
-	req, err := http.NewRequest(http.MethodPost, url.String(), bytes.NewBuffer(jsonContent))
-	if err != nil {
-		return nil, err
-	}
-	sig, err := c.Sign(http.MethodPost, endpoint, content)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set(hdrAcceptKey, jsonContentType)
-	req.Header.Set(hdrContentTypeKey, jsonContentType)
-	req.Header.Set(hdrDateKey, getGMTTimestamp())
-	req.Header.Set(hdrAuthorizationKey, sig)
-	return req, nil
+	return c.buildSignedRequest(http.MethodPost, endpoint, content)
 }
 
 // BuildPutRequest :
@@ -145,24 +113,7 @@ func (c Client) BuildPostRequest(endpoint string, content string) (*http.Request
 //	http.Request: Http Request struct that can be used via Http Client to make the request.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) BuildPutRequest(endpoint string, content string) (*http.Request, error) {
-	url, err := buildEndpointURL(c.SSL, c.Host, c.Port, c.Realm, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	jsonContent := []byte(content)
-	req, err := http.NewRequest(http.MethodPut, url.String(), bytes.NewBuffer(jsonContent))
-	if err != nil {
-		return nil, err
-	}
-	sig, err := c.Sign(http.MethodPut, endpoint, content)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set(hdrAcceptKey, jsonContentType)
-	req.Header.Set(hdrContentTypeKey, jsonContentType)
-	req.Header.Set(hdrDateKey, getGMTTimestamp())
-	req.Header.Set(hdrAuthorizationKey, sig)
-	return req, nil
+	return c.buildSignedRequest(http.MethodPut, endpoint, content)
 }
 
 // BuildEmptyPutRequest :
@@ -174,27 +125,55 @@ func (c Client) BuildPutRequest(endpoint string, content string) (*http.Request,
 //	http.Request: Http Request struct that can be used via Http Client to make the request.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) BuildEmptyPutRequest(endpoint string) (*http.Request, error) {
+	return c.buildSignedRequest(http.MethodPut, endpoint, "")
+}
+
+// BuildRequest :
+//	Function supporting the building of requests for any supported method (GET, POST, PUT, DELETE, PATCH). Will handle signing and creation of the auth header as well as timestamp and other headers needed.
+// Parameters:
+//	[Required] method: the http verb of the request.
+//	[Required] endpoint: the api endpoint (after the SecureAuth# realm) that the request will be performed against.
+//	content: the json content to be sent to the api endpoint. Required for POST and PATCH, not allowed for GET.
+// Returns:
+//	http.Request: Http Request struct that can be used via Http Client to make the request.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (c Client) BuildRequest(method string, endpoint string, content string) (*http.Request, error) {
+	if method == http.MethodGet && content != "" {
+		return nil, errors.New("GET requests cannot have content")
+	}
+	return c.buildSignedRequest(method, endpoint, content)
+}
+
+// buildSignedRequest :
+//	non-exportable helper shared by the Build*Request functions. The request is signed once and the timestamp
+//	that was signed is used for both the Date and X-SA-Date headers.
+func (c Client) buildSignedRequest(method string, endpoint string, content string) (*http.Request, error) {
 	url, err := buildEndpointURL(c.SSL, c.Host, c.Port, c.Realm, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPut, url.String(), nil)
+	var body io.Reader
+	if content != "" {
+		body = bytes.NewBufferString(content)
+	}
+	req, err := http.NewRequest(method, url.String(), body)
 	if err != nil {
 		return nil, err
 	}
-	sig, err := c.Sign(http.MethodPut, endpoint, "")
+	sig, timestamp, err := c.SignWithTimestamp(method, endpoint, content)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set(hdrAcceptKey, jsonContentType)
 	req.Header.Set(hdrContentTypeKey, jsonContentType)
-	req.Header.Set(hdrDateKey, getGMTTimestamp())
+	req.Header.Set(hdrDateKey, timestamp)
+	req.Header.Set(hdrSADateKey, timestamp)
 	req.Header.Set(hdrAuthorizationKey, sig)
 	return req, nil
 }
 
 // Do :
-//	Function to execute a Http Request.
+//	Function to execute a Http Request. The request is executed with whatever context it already carries.
 // Parameters:
 //	[Required] c: passing in the client containing authorization and host information.
 //	[Required] req: http.Request struct to execute.
@@ -202,72 +181,138 @@ func (c Client) BuildEmptyPutRequest(endpoint string) (*http.Request, error) {
 //	http.Response: Http Response struct that can be used to get the body for the api response.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) Do(req *http.Request) (*http.Response, error) {
-	httpClient := new(http.Client)
-	transport := new(http.Transport)
-	if c.BypassCertValidation {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		httpClient.Transport = transport
-	} else {
-		// If the bypass flag is not set, consider us in a "production" environment and
-		// lock down TLS settings appropriately.
-		transport.TLSClientConfig = &tls.Config{
-			MinVersion:               tls.VersionTLS12,
-			MaxVersion:               tls.VersionTLS12,
-			CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-			PreferServerCipherSuites: true,
-			CipherSuites: []uint16{
-				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_RSA_WITH_AES_256_CBC_SHA,
-			},
-		}
-		httpClient.Transport = transport
-	}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	return c.DoContext(req.Context(), req)
+}
+
+// DoContext :
+//	Function to execute a Http Request bound to a context. Cancellation and deadlines on ctx are propagated
+//	into the underlying http request, so an in-flight call is aborted as soon as ctx is done.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] req: http.Request struct to execute.
+// Returns:
+//	http.Response: Http Response struct that can be used to get the body for the api response.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (c Client) DoContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if ctx == nil {
+		return nil, errors.New("A non-nil context is required")
 	}
-	if resp.StatusCode != 200 {
-		httpError, err := parseError(resp)
+	return c.caller()(req.WithContext(ctx))
+}
+
+// doAttempts :
+//	non-exportable helper running the attempts of a call, with retries, rate limiting, failover and the
+//	circuit breaker, under the context carried by req. It is the unit wrapped by the call middleware.
+func (c Client) doAttempts(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	roundTrip := c.roundTripper(c.HTTPClient())
+	attempts := c.retry.attemptsFor(req)
+	retries, failovers, failover := 0, 0, false
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if !failover {
+				if err := sleepContext(ctx, c.retry.backoff(retries)); err != nil {
+					return nil, err
+				}
+			}
+			resigned, err := c.resign(req)
+			if err != nil {
+				return nil, err
+			}
+			req = resigned
+		}
+		record, err := c.breaker.allow(req, c.now)
 		if err != nil {
 			return nil, err
 		}
-		jsonError, err := json.Marshal(httpError)
+		release, err := c.limiter.acquire(ctx)
 		if err != nil {
+			record(ctx, nil, err)
 			return nil, err
 		}
-		return nil, errors.New(string(jsonError))
+		routed, endpoint := c.hosts.route(req)
+		resp, err := roundTrip(withAttempt(routed, attempt))
+		resp = c.limiter.hold(resp, release)
+		record(ctx, resp, err)
+		failed := c.hosts.report(ctx, endpoint, resp, err)
+		// An endpoint failure is re-sent to the next healthy endpoint right away, whatever the retry policy, as
+		// long as the request is safe to repeat. Only the other attempts count against the policy.
+		failover = failed && failovers < c.hosts.size()-1 && c.hosts.anyHealthy() && c.retry.retryable(req)
+		retry := !failover && retries+1 < attempts && c.retry.shouldRetry(ctx, resp, err)
+		if failover || retry {
+			if failover {
+				failovers++
+			} else {
+				retries++
+			}
+			if resp != nil {
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			return nil, parseError(req, resp)
+		}
+		if c.verifySignatures {
+			if err := c.verifyResponse(resp); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
 	}
-	return resp, nil
 }
 
 // Sign :
 //	Function to create the Authorization headed needed to perform API calls to SecureAuth.
 // Parameters:
 //	[Required] c: passing in the client containing authorization and host information.
-//	[Required] method: the http verb of the method being used (GET, POST, PUT)
+//	[Required] method: the http verb of the method being used (GET, POST, PUT, DELETE, PATCH)
 //	[Required] endpoint: the api endpoint (after the SecureAuth# realm) that the get will be performed against.
 //	content: the json content to be put to the api endpoint.
 // Returns:
 //	string: Authorization header string.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (c Client) Sign(method string, endpoint string, content string) (string, error) {
+	sig, _, err := c.SignWithTimestamp(method, endpoint, content)
+	return sig, err
+}
+
+// SignWithTimestamp :
+//	Function to create the Authorization header needed to perform API calls to SecureAuth, returning the
+//	timestamp that was signed so it can be sent unchanged in the Date and X-SA-Date headers. The timestamp
+//	comes from the client clock (see WithClock) and the primary AppKey is used (see PromoteKey).
+// Parameters:
+//	[Required] method: the http verb of the method being used (GET, POST, PUT, DELETE, PATCH)
+//	[Required] endpoint: the api endpoint (after the SecureAuth# realm) that the request will be performed against.
+//	content: the json content to be sent to the api endpoint.
+// Returns:
+//	string: Authorization header string.
+//	string: the GMT timestamp that was signed.
+//	Error: If an error is encountered, both strings will be empty and the error must be handled.
+func (c Client) SignWithTimestamp(method string, endpoint string, content string) (string, string, error) {
 	if !validators.ValidateHTTPMethod(method) {
-		return "", errors.New("Method invalid. Try using: POST, GET, or PUT")
+		return "", "", errors.New("Method invalid. Try using: POST, GET, PUT, DELETE or PATCH")
 	}
 	if len(endpoint) <= 0 {
-		return "", errors.New("A API endpoint is required")
+		return "", "", errors.New("A API endpoint is required")
 	}
-	if strings.Contains(method, "POST") {
+	if method == http.MethodPost || method == http.MethodPatch {
 		if len(content) <= 0 {
-			return "", errors.New("PUT and POST methods require content")
+			return "", "", errors.New("POST and PATCH methods require content")
 		}
 	}
 	var buffer bytes.Buffer
-	timestamp := getGMTTimestamp()
+	timestamp := getGMTTimestamp(c.now())
 	payload := buildAuthPayload(method, timestamp, c.AppID, c.Realm, endpoint, content)
-	encryptStr := makeHmac(payload, c.AppKey)
+	encryptStr, err := makeHmac(payload, c.primaryKey())
+	if err != nil {
+		return "", "", err
+	}
 	buffer.WriteString(c.AppID)
 	buffer.WriteString(":")
 	buffer.WriteString(string(encryptStr))
@@ -275,7 +320,40 @@ func (c Client) Sign(method string, endpoint string, content string) (string, er
 	buffer.Reset()
 	buffer.WriteString("Basic ")
 	buffer.WriteString(authStr)
-	return buffer.String(), nil
+	return buffer.String(), timestamp, nil
+}
+
+// resign :
+//	non-exportable helper to sign a copy of an already built request again with a fresh timestamp, so it can be
+//	sent once more (e.g. on retries) without being rejected as stale.
+func (c Client) resign(req *http.Request) (*http.Request, error) {
+	endpoint := strings.TrimPrefix(req.URL.EscapedPath(), "/"+c.Realm)
+	if req.URL.RawQuery != "" {
+		endpoint += "?" + req.URL.RawQuery
+	}
+	content := ""
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		content = string(raw)
+		retry.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	}
+	sig, timestamp, err := c.SignWithTimestamp(req.Method, endpoint, content)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set(hdrDateKey, timestamp)
+	retry.Header.Set(hdrSADateKey, timestamp)
+	retry.Header.Set(hdrAuthorizationKey, sig)
+	return retry, nil
 }
 
 // NewClient :
@@ -292,14 +370,37 @@ func (c Client) Sign(method string, endpoint string, content string) (string, er
 //	Client: a pointer to a client struct with the supplied values.
 //	Error: If an error is encountered, response will be nil and the error must be handled
 func NewClient(appID string, appKey string, host string, port int, realm string, ssl bool, bypassCert bool) (*Client, error) {
+	return NewClientWithOptions(appID, appKey, host, port, realm, ssl, bypassCert)
+}
+
+// NewClientWithOptions :
+//	Helper function to create a Client struct with optional configuration applied on top of the defaults.
+//	Without options the client owns a long-lived, pooled transport that is reused by every call.
+// Parameters:
+//	[Required] appId: SecureAuth API AppId.
+//	[Required] appKey: SecureAuth API AppKey.
+//	[Required] host: the host name (fully qualified/dns route-able) of the SecureAuth server.
+//	[Required] port: the port that SecureAuth's web service is running on.
+//	[Required] realm: the SecureAuth realm that will be serving the APIs.
+//	[Required] ssl: if the SecureAuth realm/web service is running over ssl, set to true.
+//	[Required] bypassCert: bypass certificate validation.
+//	opts: ClientOption values such as WithHTTPClient, WithTransport or WithTimeout.
+// Returns:
+//	Client: a pointer to a client struct with the supplied values.
+//	Error: If an error is encountered, response will be nil and the error must be handled
+func NewClientWithOptions(appID string, appKey string, host string, port int, realm string, ssl bool, bypassCert bool, opts ...ClientOption) (*Client, error) {
 	params := map[string]string{"AppID": appID, "AppKey": appKey, "Host": host, "Realm": realm}
 	valid, err := validators.ValidateClientParams(params)
 	if (!valid) && (err != nil) {
 		return nil, errors.New(err.Error())
 	}
+	if !validators.ValidateAppKey(appKey) {
+		return nil, ErrInvalidAppKey
+	}
 	c := new(Client)
 	c.AppID = appID
 	c.AppKey = appKey
+	c.keys = newKeyRing(appKey)
 	c.Host = host
 	if port == 0 {
 		c.Port = 443
@@ -309,37 +410,25 @@ func NewClient(appID string, appKey string, host string, port int, realm string,
 	c.Realm = realm
 	c.SSL = ssl
 	c.BypassCertValidation = bypassCert
-
-	return c, nil
-}
-
-// parseError :
-//  non-exportable helper to parse error response codes between handled (loosely expected http status)
-//  and non-handled status codes.
-func parseError(response *http.Response) (*HTTPError, error) {
-	httpError := new(HTTPError)
-	handledStatus := []int{400, 404, 500}
-	for _, status := range handledStatus {
-		if status == response.StatusCode {
-			if err := json.NewDecoder(response.Body).Decode(httpError); err != nil {
-				return nil, err
-			}
-			httpError.Code = response.StatusCode
-			httpError.StatusText = http.StatusText(httpError.Code)
-			return httpError, nil
+	c.httpClient = &http.Client{Transport: newTransport(bypassCert)}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.hosts != nil {
+		if len(c.hosts.statuses) == 0 {
+			return nil, errors.New("WithHealthCheck requires WithEndpoints")
 		}
+		c.startHealthChecks()
 	}
-	httpError.Code = response.StatusCode
-	httpError.StatusText = http.StatusText(httpError.Code)
-	httpError.Status = response.Status
-	httpError.Message = httpError.StatusText
-	return httpError, nil
+	return c, nil
 }
 
 // getGMTTimestamp :
 //	non-exportable helper to build the GMT timestamp used in authorization and http headers.
-func getGMTTimestamp() string {
-	time := time.Now().UTC().Format(time.RFC1123)
+func getGMTTimestamp(t time.Time) string {
+	time := t.UTC().Format(time.RFC1123)
 	if strings.Contains(time, "UTC") {
 		// The go time library formats RFC1123 incorrectly. It places UTC at the end instead
 		// of the RFC stipulated GMT. This causes issues with server side implementations that
@@ -368,7 +457,7 @@ func buildAuthPayload(method string, timestamp string, appid string, realm strin
 		buffer.WriteString("/")
 		buffer.WriteString(realm)
 		buffer.WriteString(endpoint)
-	case "POST", "PUT":
+	case "POST", "PUT", "PATCH", "DELETE":
 		if content == "" {
 			buffer.WriteString(method)
 			buffer.WriteString("\n")
@@ -416,10 +505,13 @@ func buildEndpointURL(ssl bool, host string, port int, realm string, endpoint st
 
 // makeHmac:
 //	non-exportable helper to do SHA256 HMAC
-func makeHmac(data string, key string) string {
-	byteKey, _ := hex.DecodeString(key)
+func makeHmac(data string, key string) (string, error) {
+	byteKey, err := hex.DecodeString(key)
+	if err != nil {
+		return "", ErrInvalidAppKey
+	}
 	byteData := []byte(data)
 	sig := hmac.New(sha256.New, byteKey)
 	sig.Write([]byte(byteData))
-	return base64.StdEncoding.EncodeToString(sig.Sum(nil))
+	return base64.StdEncoding.EncodeToString(sig.Sum(nil)), nil
 }
@@ -1,10 +1,11 @@
-package saidp_sdk_go
+package saidp_sdk_go_test
 
 import (
 	"encoding/json"
 	"fmt"
 	"testing"
 
+	sa "github.com/secureauthcorp/saidp-sdk-go"
 	"github.com/secureauthcorp/saidp-sdk-go/services/factors"
 )
 
@@ -45,7 +46,7 @@ const (
 )
 
 func TestClient(t *testing.T) {
-	client, err := NewClient(appID, appKey, host, port, realm, true, false)
+	client, err := sa.NewClient(appID, appKey, host, port, realm, true, false)
 	if err != nil {
 		fmt.Println(err)
 		t.FailNow()
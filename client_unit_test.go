@@ -0,0 +1,128 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+const (
+	uAppID  = "12345"
+	uAppKey = "123456"
+	uRealm  = "secureauth1"
+	uUser   = "user"
+)
+
+func TestDoContext_Unit(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.DoContext(ctx, req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("DoContext did not return promptly after the deadline")
+	}
+}
+
+// newTestClient :
+//	non-exportable helper to build a client pointed at an http or https test server, with optional client options.
+func newTestClient(t *testing.T, server *httptest.Server, opts ...ClientOption) *Client {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientWithOptions(uAppID, uAppKey, u.Hostname(), port, uRealm, u.Scheme == "https", false, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestBuildRequest_Unit(t *testing.T) {
+	client, err := NewClient(uAppID, uAppKey, "localhost", 443, uRealm, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(string) (string, error) { return uAppKey, nil }
+	for _, tc := range []struct {
+		method, content string
+	}{
+		{http.MethodDelete, ""},
+		{http.MethodDelete, `{"reason":"offboarding"}`},
+		{http.MethodPatch, `{"properties":{"firstName":"John"}}`},
+	} {
+		req, err := client.BuildRequest(tc.method, "/api/v1/users/"+uUser, tc.content)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.method, err)
+		}
+		if req.Method != tc.method || req.Header.Get("X-SA-Date") == "" {
+			t.Errorf("%s: unexpected request %v", tc.method, req)
+		}
+		if _, err := VerifyRequest(req, lookup, 0); err != nil {
+			t.Errorf("%s: %v", tc.method, err)
+		}
+	}
+	if _, err := client.BuildRequest(http.MethodPatch, "/api/v1/users/"+uUser, ""); err == nil {
+		t.Error("expected PATCH without content to fail")
+	}
+	if _, err := client.BuildRequest(http.MethodGet, "/api/v1/users/"+uUser, "{}"); err == nil {
+		t.Error("expected GET with content to fail")
+	}
+	if _, err := client.BuildRequest(http.MethodOptions, "/api/v1/users/"+uUser, ""); err == nil {
+		t.Error("expected unsupported methods to fail")
+	}
+}
+
@@ -0,0 +1,82 @@
+package saidp_sdk_go
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestSigningClock_Unit(t *testing.T) {
+	// The clock crosses a second boundary between calls; every builder must still sign and send one timestamp.
+	ticks := []time.Time{time.Date(2017, 5, 1, 12, 0, 0, 999999999, time.UTC), time.Date(2017, 5, 1, 12, 0, 1, 0, time.UTC)}
+	calls := 0
+	clock := func() time.Time {
+		now := ticks[calls%len(ticks)]
+		calls++
+		return now
+	}
+	client, err := NewClientWithOptions(uAppID, uAppKey, "localhost", 443, uRealm, true, false, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(string) (string, error) { return uAppKey, nil }
+	build := map[string]func() (*http.Request, error){
+		"get": func() (*http.Request, error) {
+			return client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		},
+		"post": func() (*http.Request, error) {
+			return client.BuildPostRequest("/api/v1/auth", `{"type":"user_id"}`)
+		},
+		"put": func() (*http.Request, error) {
+			return client.BuildPutRequest("/api/v1/users/"+uUser, `{}`)
+		},
+		"empty put": func() (*http.Request, error) {
+			return client.BuildEmptyPutRequest("/api/v1/users/" + uUser + "/throttle")
+		},
+	}
+	for name, fn := range build {
+		calls = 0
+		req, err := fn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Header.Get("Date") != "Mon, 01 May 2017 12:00:00 GMT" || req.Header.Get("X-SA-Date") != req.Header.Get("Date") {
+			t.Errorf("%s: inconsistent timestamps Date=%q X-SA-Date=%q", name, req.Header.Get("Date"), req.Header.Get("X-SA-Date"))
+		}
+		if _, err := VerifyRequest(req, lookup, 0); err != nil {
+			t.Errorf("%s: signature does not match the Date header: %v", name, err)
+		}
+	}
+	calls = 0
+	sig, timestamp, err := client.SignWithTimestamp(http.MethodGet, "/api/v1/users/"+uUser+"/factors", "")
+	if err != nil || timestamp != "Mon, 01 May 2017 12:00:00 GMT" || sig == "" {
+		t.Errorf("unexpected signature %q timestamp %q err %v", sig, timestamp, err)
+	}
+}
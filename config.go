@@ -0,0 +1,273 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Environment variables read by LoadConfig and NewClientFromEnv.
+const (
+	EnvConfigFile           = "SAIDP_CONFIG"
+	EnvAppID                = "SAIDP_APP_ID"
+	EnvAppKey               = "SAIDP_APP_KEY"
+	EnvAppKeyFile           = "SAIDP_APP_KEY_FILE"
+	EnvAppKeySecret         = "SAIDP_APP_KEY_SECRET"
+	EnvSecretsDir           = "SAIDP_SECRETS_DIR"
+	EnvHost                 = "SAIDP_HOST"
+	EnvPort                 = "SAIDP_PORT"
+	EnvRealm                = "SAIDP_REALM"
+	EnvSSL                  = "SAIDP_SSL"
+	EnvBypassCertValidation = "SAIDP_BYPASS_CERT_VALIDATION"
+	EnvTimeout              = "SAIDP_TIMEOUT"
+)
+
+// SecretProvider :
+//	Source of secrets such as the AppKey, e.g. Vault, a cloud secret manager or a file mount.
+type SecretProvider interface {
+	// GetSecret returns the value of the named secret.
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// SecretProviderFunc :
+//	Adapter to use a function as a SecretProvider.
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+// GetSecret :
+//	Implements SecretProvider by calling f.
+func (f SecretProviderFunc) GetSecret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// FileSecretProvider :
+//	SecretProvider reading each secret from a file named after it in Dir, the layout used by Kubernetes and
+//	Docker secret mounts. Surrounding whitespace is trimmed. Dir must be an absolute path so secrets never
+//	depend on the working directory of the process.
+type FileSecretProvider struct {
+	Dir string
+}
+
+// GetSecret :
+//	Implements SecretProvider by reading Dir/name.
+func (p FileSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if !filepath.IsAbs(p.Dir) {
+		return "", fmt.Errorf("FileSecretProvider requires an absolute Dir, got %q", p.Dir)
+	}
+	if name == "" || strings.Contains(name, "..") {
+		return "", fmt.Errorf("Invalid secret name %q", name)
+	}
+	return readSecretFile(filepath.Join(p.Dir, name))
+}
+
+// Config :
+//	Client settings loaded from a JSON file and/or SAIDP_* environment variables. YAML files are loaded by the
+//	config subpackage so that the yaml dependency stays out of this package.
+// Fields:
+//	[Required] AppID: SecureAuth API AppId (SAIDP_APP_ID).
+//	AppKey: SecureAuth API AppKey (SAIDP_APP_KEY). One of AppKey, AppKeyFile or AppKeySecret is required.
+//	AppKeyFile: path of a file holding the AppKey (SAIDP_APP_KEY_FILE).
+//	AppKeySecret: name of the secret holding the AppKey, resolved with a SecretProvider (SAIDP_APP_KEY_SECRET).
+//	[Required] Host: FQDN of the SecureAuth IdP (SAIDP_HOST).
+//	Port: port of the web service, 443 when zero (SAIDP_PORT).
+//	[Required] Realm: the realm, e.g. secureauth1 (SAIDP_REALM).
+//	SSL: use https, true when not set (SAIDP_SSL).
+//	BypassCertValidation: skip certificate validation, for test environments only (SAIDP_BYPASS_CERT_VALIDATION).
+//	Timeout: overall timeout of a call such as "15s" (SAIDP_TIMEOUT).
+type Config struct {
+	AppID                string `json:"appId" yaml:"appId"`
+	AppKey               string `json:"appKey" yaml:"appKey"`
+	AppKeyFile           string `json:"appKeyFile" yaml:"appKeyFile"`
+	AppKeySecret         string `json:"appKeySecret" yaml:"appKeySecret"`
+	Host                 string `json:"host" yaml:"host"`
+	Port                 int    `json:"port" yaml:"port"`
+	Realm                string `json:"realm" yaml:"realm"`
+	SSL                  *bool  `json:"ssl" yaml:"ssl"`
+	BypassCertValidation bool   `json:"bypassCertValidation" yaml:"bypassCertValidation"`
+	Timeout              string `json:"timeout" yaml:"timeout"`
+}
+
+// LoadConfig :
+//	Function loading a Config from a JSON (.json) file, then applying any SAIDP_* environment variables on top
+//	of it. Use config.Load from the config subpackage for YAML files.
+// Parameters:
+//	path: the configuration file; when empty only the environment is read.
+// Returns:
+//	Config: the loaded configuration.
+//	Error: If an error is encountered, config will be nil and the error must be handled.
+func LoadConfig(path string) (*Config, error) {
+	cfg := new(Config)
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			err = json.Unmarshal(content, cfg)
+		case ".yaml", ".yml":
+			err = fmt.Errorf("YAML configuration file %q must be loaded with the config subpackage", path)
+		default:
+			err = fmt.Errorf("Unsupported configuration file %q, use .json", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// NewClientFromEnv :
+//	Helper function to create a Client from the SAIDP_* environment variables, and from the JSON file named by
+//	SAIDP_CONFIG when set. SAIDP_APP_KEY_SECRET is resolved as a file in the absolute SAIDP_SECRETS_DIR directory.
+// Parameters:
+//	opts: additional client options.
+// Returns:
+//	Client: Client struct populated from the environment.
+//	Error: If an error is encountered, client will be nil and the error must be handled.
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	cfg, err := LoadConfig(os.Getenv(EnvConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(context.Background(), *cfg, FileSecretProvider{Dir: os.Getenv(EnvSecretsDir)}, opts...)
+}
+
+// NewClientFromConfig :
+//	Helper function to create a Client from a Config. The AppKey is taken from AppKey, AppKeyFile or, through
+//	secrets, AppKeySecret, and must be hex encoded.
+// Parameters:
+//	[Required] ctx: context bounding the secret lookup.
+//	[Required] cfg: the client configuration.
+//	secrets: provider resolving AppKeySecret; may be nil when AppKeySecret is not used.
+//	opts: additional client options, applied after the configured timeout.
+// Returns:
+//	Client: Client struct populated from the configuration.
+//	Error: If an error is encountered, client will be nil and the error must be handled.
+func NewClientFromConfig(ctx context.Context, cfg Config, secrets SecretProvider, opts ...ClientOption) (*Client, error) {
+	appKey, err := cfg.resolveAppKey(ctx, secrets)
+	if err != nil {
+		return nil, err
+	}
+	ssl := true
+	if cfg.SSL != nil {
+		ssl = *cfg.SSL
+	}
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid timeout %q: %v", cfg.Timeout, err)
+		}
+		opts = append([]ClientOption{WithTimeout(timeout)}, opts...)
+	}
+	return NewClientWithOptions(cfg.AppID, appKey, cfg.Host, cfg.Port, cfg.Realm, ssl, cfg.BypassCertValidation, opts...)
+}
+
+// resolveAppKey :
+//	non-exportable helper returning the AppKey from the first configured source.
+func (cfg Config) resolveAppKey(ctx context.Context, secrets SecretProvider) (string, error) {
+	switch {
+	case cfg.AppKey != "":
+		return cfg.AppKey, nil
+	case cfg.AppKeyFile != "":
+		return readSecretFile(cfg.AppKeyFile)
+	case cfg.AppKeySecret != "":
+		if secrets == nil {
+			return "", errors.New("A secret provider is required to resolve AppKeySecret")
+		}
+		key, err := secrets.GetSecret(ctx, cfg.AppKeySecret)
+		if err != nil {
+			return "", fmt.Errorf("Unable to resolve AppKey secret %q: %v", cfg.AppKeySecret, err)
+		}
+		return strings.TrimSpace(key), nil
+	}
+	return "", errors.New("AppKey is required for creating a new client")
+}
+
+// ApplyEnv :
+//	Function overriding the configuration with the SAIDP_* environment variables that are set.
+// Returns:
+//	Error: If a variable holds an invalid value, the error must be handled.
+func (cfg *Config) ApplyEnv() error {
+	for env, field := range map[string]*string{
+		EnvAppID:        &cfg.AppID,
+		EnvAppKey:       &cfg.AppKey,
+		EnvAppKeyFile:   &cfg.AppKeyFile,
+		EnvAppKeySecret: &cfg.AppKeySecret,
+		EnvHost:         &cfg.Host,
+		EnvRealm:        &cfg.Realm,
+		EnvTimeout:      &cfg.Timeout,
+	} {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = value
+		}
+	}
+	if value, ok := os.LookupEnv(EnvPort); ok {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid %s %q", EnvPort, value)
+		}
+		cfg.Port = port
+	}
+	if value, ok := os.LookupEnv(EnvSSL); ok {
+		ssl, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Invalid %s %q", EnvSSL, value)
+		}
+		cfg.SSL = &ssl
+	}
+	if value, ok := os.LookupEnv(EnvBypassCertValidation); ok {
+		bypass, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Invalid %s %q", EnvBypassCertValidation, value)
+		}
+		cfg.BypassCertValidation = bypass
+	}
+	return nil
+}
+
+// readSecretFile :
+//	non-exportable helper reading a secret from a file, trimming surrounding whitespace.
+func readSecretFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,88 @@
+// Package config loads saidp_sdk_go client configuration from YAML files. It lives in its own package so that
+// programs configured through JSON or the environment do not depend on a YAML library.
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Load :
+//	Function loading a Config from a YAML (.yaml, .yml) or JSON (.json) file, then applying any SAIDP_*
+//	environment variables on top of it.
+// Parameters:
+//	path: the configuration file; when empty only the environment is read.
+// Returns:
+//	Config: the loaded configuration.
+//	Error: If an error is encountered, config will be nil and the error must be handled.
+func Load(path string) (*sa.Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return sa.LoadConfig(path)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(sa.Config)
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("Invalid configuration file %q: %v", path, err)
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// NewClientFromEnv :
+//	Helper function to create a Client from the SAIDP_* environment variables, and from the YAML or JSON file
+//	named by SAIDP_CONFIG when set. SAIDP_APP_KEY_SECRET is resolved as a file in the absolute SAIDP_SECRETS_DIR
+//	directory.
+// Parameters:
+//	opts: additional client options.
+// Returns:
+//	Client: Client struct populated from the environment.
+//	Error: If an error is encountered, client will be nil and the error must be handled.
+func NewClientFromEnv(opts ...sa.ClientOption) (*sa.Client, error) {
+	cfg, err := Load(os.Getenv(sa.EnvConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	secrets := sa.FileSecretProvider{Dir: os.Getenv(sa.EnvSecretsDir)}
+	return sa.NewClientFromConfig(context.Background(), *cfg, secrets, opts...)
+}
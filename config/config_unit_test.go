@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+const (
+	uAppID  = "12345"
+	uAppKey = "123456"
+	uRealm  = "secureauth1"
+)
+
+func TestLoad_Unit(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := dir + "/saidp.yaml"
+	yamlContent := "appId: " + uAppID + "\nappKeySecret: saidp-key\nhost: idp.example.com\nport: 8443\nrealm: " + uRealm + "\nssl: false\ntimeout: 5s\n"
+	if err := ioutil.WriteFile(yamlPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dir+"/saidp-key", []byte(uAppKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(sa.EnvHost, "override.example.com")
+	cfg, err := Load(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "override.example.com" || cfg.Port != 8443 || cfg.SSL == nil || *cfg.SSL || cfg.AppKeySecret != "saidp-key" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	t.Setenv(sa.EnvConfigFile, yamlPath)
+	t.Setenv(sa.EnvSecretsDir, dir)
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.AppKey != uAppKey || client.SSL || client.HTTPClient().Timeout != 5*time.Second {
+		t.Errorf("unexpected client %+v", client)
+	}
+
+	jsonPath := dir + "/saidp.json"
+	if err := ioutil.WriteFile(jsonPath, []byte(`{"appId":"`+uAppID+`","host":"idp.example.com","realm":"`+uRealm+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := Load(jsonPath); err != nil || cfg.AppID != uAppID {
+		t.Errorf("expected JSON files to be loaded too, got %+v %v", cfg, err)
+	}
+	if err := ioutil.WriteFile(yamlPath, []byte("appId: [\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(yamlPath); err == nil {
+		t.Error("expected invalid YAML to fail")
+	}
+}
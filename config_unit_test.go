@@ -0,0 +1,111 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestConfig_Unit(t *testing.T) {
+	if _, err := NewClient(uAppID, "not-hex", "localhost", 443, uRealm, true, false); !errors.Is(err, ErrInvalidAppKey) {
+		t.Errorf("expected ErrInvalidAppKey, got %v", err)
+	}
+
+	dir := t.TempDir()
+	filePath := dir + "/saidp-secret.json"
+	fileContent := `{"appId":"` + uAppID + `","appKeySecret":"saidp-key","host":"idp.example.com","port":8443,"realm":"` + uRealm + `","ssl":false,"timeout":"5s"}`
+	if err := ioutil.WriteFile(filePath, []byte(fileContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dir+"/saidp-key", []byte(uAppKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvHost, "override.example.com")
+	cfg, err := LoadConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "override.example.com" || cfg.Port != 8443 || cfg.SSL == nil || *cfg.SSL || cfg.AppKeySecret != "saidp-key" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if _, err := NewClientFromConfig(context.Background(), *cfg, nil); err == nil {
+		t.Error("expected AppKeySecret without a provider to fail")
+	}
+	client, err := NewClientFromConfig(context.Background(), *cfg, FileSecretProvider{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.AppKey != uAppKey || client.SSL || client.HTTPClient().Timeout != 5*time.Second {
+		t.Errorf("unexpected client %+v", client)
+	}
+
+	jsonPath := dir + "/saidp.json"
+	if err := ioutil.WriteFile(jsonPath, []byte(`{"appId":"`+uAppID+`","host":"idp.example.com","realm":"`+uRealm+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvConfigFile, jsonPath)
+	t.Setenv(EnvAppKey, uAppKey)
+	client, err = NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Host != "override.example.com" || client.Port != 443 || !client.SSL || client.AppKey != uAppKey {
+		t.Errorf("unexpected client %+v", client)
+	}
+	secrets := SecretProviderFunc(func(ctx context.Context, name string) (string, error) { return "zz", nil })
+	if _, err := NewClientFromConfig(context.Background(), Config{AppID: uAppID, AppKeySecret: "k", Host: "h", Realm: uRealm}, secrets); !errors.Is(err, ErrInvalidAppKey) {
+		t.Errorf("expected ErrInvalidAppKey, got %v", err)
+	}
+	if _, err := (FileSecretProvider{Dir: "secrets"}).GetSecret(context.Background(), "saidp-key"); err == nil {
+		t.Error("expected a relative secrets directory to be rejected")
+	}
+	if err := ioutil.WriteFile(dir+"/saidp.yaml", []byte("appId: "+uAppID+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(dir + "/saidp.yaml"); err == nil {
+		t.Error("expected YAML files to be left to the config subpackage")
+	}
+	os.Unsetenv(EnvAppKey)
+	t.Setenv(EnvAppKeySecret, "saidp-key")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Error("expected SAIDP_APP_KEY_SECRET without SAIDP_SECRETS_DIR to fail")
+	}
+	t.Setenv(EnvSecretsDir, dir)
+	if _, err := NewClientFromEnv(); err != nil {
+		t.Errorf("expected the secret to be read from SAIDP_SECRETS_DIR, got %v", err)
+	}
+	t.Setenv(EnvPort, "https")
+	if _, err := LoadConfig(""); err == nil {
+		t.Error("expected an invalid port to fail")
+	}
+}
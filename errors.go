@@ -0,0 +1,126 @@
+package saidp_sdk_go
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Sentinel errors describing the failure class of an HTTPError. Use errors.Is to branch on them:
+//	if errors.Is(err, saidp_sdk_go.ErrNotFound) { ... }
+var (
+	ErrBadRequest   = errors.New("saidp: bad request")
+	ErrUnauthorized = errors.New("saidp: unauthorized")
+	ErrForbidden    = errors.New("saidp: forbidden")
+	ErrNotFound     = errors.New("saidp: not found")
+	ErrThrottled    = errors.New("saidp: throttled")
+	ErrServer       = errors.New("saidp: server error")
+	ErrUnexpected   = errors.New("saidp: unexpected http status")
+)
+
+// ErrInvalidAppKey : returned when an AppKey is not a valid hex encoded string and cannot be used to sign.
+var ErrInvalidAppKey = errors.New("saidp: AppKey must be hex encoded")
+
+// HTTPError :
+//	Error returned by Do/DoContext when the SecureAuth web service answers with a non 200 status.
+//	Retrieve it with errors.As to inspect the http code, the SecureAuth status/message, the endpoint and the raw body.
+// Fields:
+//	Status: SecureAuth status from the response body, or the http status line when the body is not json.
+//	Message: SecureAuth message from the response body, or the http status text when the body is not json.
+//	Code: http status code.
+//	StatusText: http status text for Code.
+//	Method: http method of the failed request.
+//	Endpoint: path of the failed request, including the realm.
+//	Body: raw response body.
+type HTTPError struct {
+	Status     string `json:"status,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Code       int    `json:",omitempty"`
+	StatusText string `json:",omitempty"`
+	Method     string `json:"-"`
+	Endpoint   string `json:"-"`
+	Body       []byte `json:"-"`
+}
+
+// Error :
+//	Implements the error interface. The message keeps the json form returned by earlier versions of the SDK.
+func (e *HTTPError) Error() string {
+	jsonError, err := json.Marshal(e)
+	if err != nil {
+		return e.StatusText
+	}
+	return string(jsonError)
+}
+
+// Unwrap :
+//	Returns the sentinel error matching the http code so errors.Is can be used against ErrNotFound, ErrThrottled, etc.
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.Code == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.Code == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.Code == http.StatusForbidden:
+		return ErrForbidden
+	case e.Code == http.StatusNotFound:
+		return ErrNotFound
+	case e.Code == http.StatusTooManyRequests:
+		return ErrThrottled
+	case e.Code >= 500:
+		return ErrServer
+	}
+	return ErrUnexpected
+}
+
+// parseError :
+//	non-exportable helper to build an HTTPError from a non 200 response. The body is always consumed and closed;
+//	when it holds the SecureAuth status/message json those values are used, otherwise the http status is used.
+func parseError(request *http.Request, response *http.Response) *HTTPError {
+	defer response.Body.Close()
+	httpError := new(HTTPError)
+	httpError.Code = response.StatusCode
+	httpError.StatusText = http.StatusText(response.StatusCode)
+	httpError.Method = request.Method
+	httpError.Endpoint = request.URL.Path
+	body, err := ioutil.ReadAll(response.Body)
+	if err == nil {
+		httpError.Body = body
+		json.Unmarshal(body, httpError)
+	}
+	if httpError.Status == "" && httpError.Message == "" {
+		httpError.Status = response.Status
+		httpError.Message = httpError.StatusText
+	}
+	return httpError
+}
+
+// ErrUnknownTenant : returned by a Registry when no client is registered under the requested tenant key.
+var ErrUnknownTenant = errors.New("saidp: unknown tenant")
@@ -0,0 +1,84 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestHTTPError_Unit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + uRealm + "/api/v1/users/missing/factors":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":"not_found","message":"User Id was not found."}`))
+		case "/" + uRealm + "/api/v1/users/busy/factors":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`<html>slow down</html>`))
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	req, err := client.BuildGetRequest("/api/v1/users/missing/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected an *HTTPError, got %T", err)
+	}
+	if httpErr.Code != 404 || httpErr.Status != "not_found" || httpErr.Message != "User Id was not found." {
+		t.Errorf("unexpected error fields: %+v", httpErr)
+	}
+	if httpErr.Endpoint != "/"+uRealm+"/api/v1/users/missing/factors" || httpErr.Method != http.MethodGet {
+		t.Errorf("unexpected endpoint: %s %s", httpErr.Method, httpErr.Endpoint)
+	}
+	if err.Error() != `{"status":"not_found","message":"User Id was not found.","Code":404,"StatusText":"Not Found"}` {
+		t.Errorf("unexpected error string: %s", err.Error())
+	}
+
+	req, err = client.BuildGetRequest("/api/v1/users/busy/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req)
+	if !errors.Is(err, ErrThrottled) || !errors.As(err, &httpErr) {
+		t.Fatalf("expected a throttled *HTTPError, got %v", err)
+	}
+	if string(httpErr.Body) != "<html>slow down</html>" || httpErr.Message != "Too Many Requests" {
+		t.Errorf("unexpected error fields: %+v", httpErr)
+	}
+}
@@ -0,0 +1,106 @@
+package saidp_sdk_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// RawReceiver :
+//	Implemented by service Response structs to receive the raw json body and the http response from Execute.
+type RawReceiver interface {
+	SetRaw(rawJSON string, httpResponse *http.Response)
+}
+
+// Execute :
+//	Shared request/response pipeline used by every service package: marshals the body, builds and signs the
+//	request, runs it through DoContext (retries, limits, signature checks...) and unmarshals the json response.
+//	When Resp implements RawReceiver the raw json and the http response are attached to it.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] method: the http verb (GET, POST, PUT, DELETE, PATCH).
+//	[Required] endpoint: the api endpoint (after the SecureAuth# realm).
+//	body: value marshaled to json as the request content, a string sent as is, or nil for no content.
+// Returns:
+//	Resp: Struct unmarshaled from the Json response from the API endpoint.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func Execute[Resp any](ctx context.Context, c *Client, method string, endpoint string, body interface{}) (*Resp, error) {
+	if c == nil {
+		return nil, errors.New("A client is required")
+	}
+	content, err := marshalContent(body)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := c.BuildRequest(method, endpoint, content)
+	if err != nil {
+		return nil, err
+	}
+	httpResponse, err := c.DoContext(ctx, httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ioutil.ReadAll(httpResponse.Body)
+	httpResponse.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response := new(Resp)
+	if err := json.Unmarshal(raw, response); err != nil {
+		return nil, err
+	}
+	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(raw))
+	if receiver, ok := interface{}(response).(RawReceiver); ok {
+		receiver.SetRaw(string(raw), httpResponse)
+	}
+	return response, nil
+}
+
+// marshalContent :
+//	non-exportable helper turning an Execute body into the request content.
+func marshalContent(body interface{}) (string, error) {
+	switch b := body.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return b, nil
+	case []byte:
+		return string(b), nil
+	}
+	content, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,86 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+type testResponse struct {
+	Status       string         `json:"status"`
+	Message      string         `json:"message"`
+	RawJSON      string         `json:"-"`
+	HTTPResponse *http.Response `json:"-"`
+}
+
+func (r *testResponse) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
+func TestExecute_Unit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPost && string(content) == `{"user_id":"user","type":"user_id"}`:
+			w.Write([]byte(`{"status":"found","message":"User Id found"}`))
+		case r.Method == http.MethodPut && len(content) == 0:
+			w.Write([]byte(`{"status":"success","message":""}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	request := struct {
+		UserID string `json:"user_id"`
+		Type   string `json:"type"`
+	}{uUser, "user_id"}
+	resp, err := Execute[testResponse](context.Background(), client, http.MethodPost, "/api/v1/auth", request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "found" || resp.RawJSON != `{"status":"found","message":"User Id found"}` || resp.HTTPResponse == nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	resp, err = Execute[testResponse](context.Background(), client, http.MethodPut, "/api/v1/users/"+uUser+"/throttle", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "success" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if _, err := Execute[testResponse](context.Background(), client, "TRACE", "/api/v1/auth", nil); err == nil {
+		t.Error("expected an unsupported method to be rejected")
+	}
+}
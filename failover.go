@@ -0,0 +1,353 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Endpoint :
+//	One deployment of the IdP web service, e.g. a site of an active/passive pair.
+// Fields:
+//	[Required] Host: the host name (fully qualified/dns route-able) of the SecureAuth server.
+//	Port: the port of the web service, 443 when zero.
+//	Priority: lower values are preferred; endpoints with the same priority keep their declaration order.
+type Endpoint struct {
+	Host     string
+	Port     int
+	Priority int
+}
+
+// HealthCheck :
+//	Settings of the periodic probes sent to every endpoint of a client built with WithEndpoints.
+// Fields:
+//	Interval: time between two probe rounds.
+//	Timeout: timeout of a single probe.
+//	Endpoint: api endpoint probed with a signed GET; any 200 response marks the IdP healthy.
+type HealthCheck struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	Endpoint string
+}
+
+// EndpointStatus :
+//	Health of an endpoint as last observed by the client.
+// Fields:
+//	Endpoint: the endpoint.
+//	Healthy: false after a failed probe or call, until a probe succeeds.
+//	Active: true for the endpoint calls are currently sent to.
+//	LastChecked: time of the last probe.
+//	LastError: error of the last failed probe or call, if any.
+type EndpointStatus struct {
+	Endpoint    Endpoint
+	Healthy     bool
+	Active      bool
+	LastChecked time.Time
+	LastError   string
+}
+
+// hostPool :
+//	non-exportable set of endpoints shared by all copies of a client.
+type hostPool struct {
+	mu       sync.RWMutex
+	statuses []EndpointStatus
+	check    HealthCheck
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// DefaultHealthCheck :
+//	Helper function returning probes every 30 seconds with a 5 second timeout against the dfp js endpoint,
+//	a cheap signed GET.
+// Returns:
+//	HealthCheck: settings that can be adjusted before use.
+func DefaultHealthCheck() HealthCheck {
+	return HealthCheck{Interval: 30 * time.Second, Timeout: 5 * time.Second, Endpoint: "/api/v1/dfp/js"}
+}
+
+// WithEndpoints :
+//	Option to spread the client over several IdP endpoints instead of the single host and port. Every call is
+//	sent to the healthy endpoint with the lowest Priority: when it fails a probe, or a call with a transport error
+//	or a 502, 503 or 504 response, calls fail over to the next one, and fail back once a probe succeeds again.
+//	The failed call itself is sent again to the next healthy endpoint when it is safe to repeat (see
+//	RetryPolicy.Retryable and DefaultRetryable), with or without a retry policy. Probes run in the background
+//	with DefaultHealthCheck unless WithHealthCheck is used, until Close is called.
+// Parameters:
+//	[Required] endpoints: one or more endpoints.
+func WithEndpoints(endpoints ...Endpoint) ClientOption {
+	return func(c *Client) error {
+		if len(endpoints) == 0 {
+			return errors.New("At least one endpoint is required")
+		}
+		statuses := make([]EndpointStatus, 0, len(endpoints))
+		for _, e := range endpoints {
+			if e.Host == "" {
+				return errors.New("Endpoint host is required")
+			}
+			if e.Port == 0 {
+				e.Port = 443
+			}
+			statuses = append(statuses, EndpointStatus{Endpoint: e, Healthy: true})
+		}
+		sort.SliceStable(statuses, func(i, j int) bool {
+			return statuses[i].Endpoint.Priority < statuses[j].Endpoint.Priority
+		})
+		check := DefaultHealthCheck()
+		if c.hosts != nil {
+			check = c.hosts.check
+		}
+		c.hosts = &hostPool{statuses: statuses, check: check, stop: make(chan struct{})}
+		return nil
+	}
+}
+
+// WithHealthCheck :
+//	Option to tune the probes of the endpoints configured with WithEndpoints.
+// Parameters:
+//	[Required] hc: the probe settings; zero fields keep the DefaultHealthCheck values.
+func WithHealthCheck(hc HealthCheck) ClientOption {
+	return func(c *Client) error {
+		if hc.Interval < 0 || hc.Timeout < 0 {
+			return errors.New("Health check interval and timeout must not be negative")
+		}
+		check := DefaultHealthCheck()
+		if hc.Interval > 0 {
+			check.Interval = hc.Interval
+		}
+		if hc.Timeout > 0 {
+			check.Timeout = hc.Timeout
+		}
+		if hc.Endpoint != "" {
+			check.Endpoint = hc.Endpoint
+		}
+		if c.hosts == nil {
+			c.hosts = &hostPool{check: check, stop: make(chan struct{})}
+			return nil
+		}
+		c.hosts.check = check
+		return nil
+	}
+}
+
+// Endpoints :
+//	Function returning the health of the endpoints of a client built with WithEndpoints, in priority order.
+// Returns:
+//	[]EndpointStatus: snapshot of the endpoints; nil for single host clients.
+func (c Client) Endpoints() []EndpointStatus {
+	if c.hosts == nil {
+		return nil
+	}
+	c.hosts.mu.RLock()
+	defer c.hosts.mu.RUnlock()
+	statuses := append([]EndpointStatus(nil), c.hosts.statuses...)
+	if active := c.hosts.activeIndex(); active >= 0 {
+		statuses[active].Active = true
+	}
+	return statuses
+}
+
+// CheckEndpoints :
+//	Function probing every endpoint now instead of waiting for the next probe round, e.g. from a readiness check.
+// Parameters:
+//	[Required] ctx: context bounding the probes.
+func (c Client) CheckEndpoints(ctx context.Context) {
+	if c.hosts == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range c.Endpoints() {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.probe(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Close :
+//	Function stopping the background probes of a client built with WithEndpoints and WithHealthCheck. The caller
+//	owns the client and must Close it once done, except for clients of a Registry, which are closed by Remove
+//	and UpdateCredentials. Other clients have nothing to release. Calls can still be made after Close, using the
+//	last known health.
+// Returns:
+//	Error: always nil.
+func (c Client) Close() error {
+	if c.hosts != nil {
+		c.hosts.once.Do(func() { close(c.hosts.stop) })
+	}
+	return nil
+}
+
+// startHealthChecks :
+//	non-exportable helper starting the probe loop once the client is fully configured. The loop reads the client
+//	through c on every round rather than holding a copy, so probes are signed with its current credentials and
+//	key ring. It runs until Close.
+func (c *Client) startHealthChecks() {
+	ticker := time.NewTicker(c.hosts.check.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.hosts.stop:
+				return
+			case <-ticker.C:
+				c.CheckEndpoints(context.Background())
+			}
+		}
+	}()
+}
+
+// probe :
+//	non-exportable helper sending a signed GET to one endpoint and recording its health.
+func (c Client) probe(ctx context.Context, i int) {
+	ctx, cancel := context.WithTimeout(ctx, c.hosts.check.Timeout)
+	defer cancel()
+	err := func() error {
+		req, err := c.BuildGetRequest(c.hosts.check.Endpoint)
+		if err != nil {
+			return err
+		}
+		resp, err := c.HTTPClient().Do(c.hosts.routeTo(req.WithContext(ctx), i))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("health check returned %s", resp.Status)
+		}
+		return nil
+	}()
+	c.hosts.mu.Lock()
+	defer c.hosts.mu.Unlock()
+	c.hosts.statuses[i].LastChecked = c.now()
+	c.hosts.setHealth(i, err)
+}
+
+// route :
+//	non-exportable helper returning a copy of req addressed to the active endpoint, and the index of that
+//	endpoint. Requests of single host clients are returned unchanged.
+func (p *hostPool) route(req *http.Request) (*http.Request, int) {
+	if p == nil || len(p.statuses) == 0 {
+		return req, -1
+	}
+	p.mu.RLock()
+	i := p.activeIndex()
+	p.mu.RUnlock()
+	return p.routeTo(req, i), i
+}
+
+// routeTo :
+//	non-exportable helper returning a copy of req addressed to endpoint i. The signature only covers the path,
+//	so the request does not need to be signed again.
+func (p *hostPool) routeTo(req *http.Request, i int) *http.Request {
+	endpoint := p.statuses[i].Endpoint
+	routed := req.Clone(req.Context())
+	routed.URL.Host = net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	routed.Host = routed.URL.Host
+	return routed
+}
+
+// report :
+//	non-exportable helper marking an endpoint unhealthy after a transport error that was not caused by ctx, or a
+//	502, 503 or 504 response. It reports whether the endpoint was marked unhealthy.
+func (p *hostPool) report(ctx context.Context, i int, resp *http.Response, err error) bool {
+	if p == nil || i < 0 || ctx.Err() != nil {
+		return false
+	}
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			err = fmt.Errorf("endpoint returned %s", resp.Status)
+		default:
+			return false
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setHealth(i, err)
+	return true
+}
+
+// size :
+//	non-exportable helper returning the number of endpoints, zero for single host clients.
+func (p *hostPool) size() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.statuses)
+}
+
+// anyHealthy :
+//	non-exportable helper reporting if at least one endpoint is healthy.
+func (p *hostPool) anyHealthy() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, status := range p.statuses {
+		if status.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// setHealth :
+//	non-exportable helper recording the outcome of a probe or call. The caller must hold the lock.
+func (p *hostPool) setHealth(i int, err error) {
+	p.statuses[i].Healthy = err == nil
+	p.statuses[i].LastError = ""
+	if err != nil {
+		p.statuses[i].LastError = err.Error()
+	}
+}
+
+// activeIndex :
+//	non-exportable helper returning the first healthy endpoint in priority order, or the first endpoint when
+//	none is healthy. The caller must hold the lock.
+func (p *hostPool) activeIndex() int {
+	if len(p.statuses) == 0 {
+		return -1
+	}
+	for i, status := range p.statuses {
+		if status.Healthy {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,257 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestFailover_Unit(t *testing.T) {
+	var primaryDown atomic.Bool
+	var primaryHits, backupHits int32
+	handler := func(hits *int32, down *atomic.Bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if down != nil && down.Load() {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			if _, err := VerifyRequest(r, func(string) (string, error) { return uAppKey, nil }, 0); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.HasSuffix(r.URL.Path, "/dfp/js") {
+				atomic.AddInt32(hits, 1)
+			}
+			w.Write([]byte(`{"status":"found","message":""}`))
+		}
+	}
+	primary := httptest.NewServer(handler(&primaryHits, &primaryDown))
+	defer primary.Close()
+	backup := httptest.NewServer(handler(&backupHits, nil))
+	defer backup.Close()
+	endpoint := func(server *httptest.Server, priority int) Endpoint {
+		u, _ := url.Parse(server.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return Endpoint{Host: u.Hostname(), Port: port, Priority: priority}
+	}
+
+	client, err := NewClientWithOptions(uAppID, uAppKey, "idp.example.com", 443, uRealm, false, false,
+		WithEndpoints(endpoint(backup, 2), endpoint(primary, 1)),
+		WithHealthCheck(HealthCheck{Interval: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	call := func() error {
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&primaryHits) != 1 || atomic.LoadInt32(&backupHits) != 0 {
+		t.Fatal("expected calls to go to the endpoint with the lowest priority")
+	}
+	primaryDown.Store(true)
+	if err := call(); err != nil {
+		t.Fatalf("expected the failed call to be sent again to the backup endpoint, got %v", err)
+	}
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&backupHits) != 2 {
+		t.Fatal("expected calls to fail over to the backup endpoint")
+	}
+	statuses := client.Endpoints()
+	if statuses[0].Healthy || statuses[0].LastError == "" || !statuses[1].Active {
+		t.Errorf("unexpected endpoint statuses %+v", statuses)
+	}
+
+	client.CheckEndpoints(context.Background())
+	if client.Endpoints()[0].Healthy {
+		t.Error("expected the probe of a down endpoint to fail")
+	}
+	primaryDown.Store(false)
+	client.CheckEndpoints(context.Background())
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&primaryHits) != 2 || atomic.LoadInt32(&backupHits) != 2 {
+		t.Error("expected calls to fail back once the primary endpoint is healthy")
+	}
+	if statuses := client.Endpoints(); !statuses[0].Active || statuses[0].LastChecked.IsZero() {
+		t.Errorf("unexpected endpoint statuses %+v", statuses)
+	}
+	if _, err := NewClientWithOptions(uAppID, uAppKey, "idp.example.com", 443, uRealm, false, false,
+		WithHealthCheck(DefaultHealthCheck())); err == nil {
+		t.Error("expected WithHealthCheck without endpoints to fail")
+	}
+}
+
+func TestFailoverStatus_Unit(t *testing.T) {
+	var primaryHits, backupHits int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryHits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupHits, 1)
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer backup.Close()
+	endpoint := func(server *httptest.Server, priority int) Endpoint {
+		u, _ := url.Parse(server.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return Endpoint{Host: u.Hostname(), Port: port, Priority: priority}
+	}
+	client, err := NewClientWithOptions(uAppID, uAppKey, "idp.example.com", 443, uRealm, false, false,
+		WithEndpoints(endpoint(primary, 1), endpoint(backup, 2)),
+		WithHealthCheck(HealthCheck{Interval: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	req, err := client.BuildPostRequest("/api/v1/auth", `{"user_id":"`+uUser+`","type":"password","token":"secret"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected a password validation to fail with the endpoint instead of being sent again")
+	}
+	if atomic.LoadInt32(&primaryHits) != 1 || atomic.LoadInt32(&backupHits) != 0 || client.Endpoints()[0].Healthy {
+		t.Fatalf("expected a 503 to mark the endpoint unhealthy, got %+v", client.Endpoints())
+	}
+
+	client.hosts.mu.Lock()
+	client.hosts.setHealth(0, nil)
+	client.hosts.mu.Unlock()
+	req, err = client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the call to be sent again to the backup endpoint without a retry policy, got %v", err)
+	}
+	resp.Body.Close()
+	if atomic.LoadInt32(&primaryHits) != 2 || atomic.LoadInt32(&backupHits) != 1 {
+		t.Errorf("expected one attempt per endpoint, got %d and %d", primaryHits, backupHits)
+	}
+}
+
+func TestHealthCheckCredentials_Unit(t *testing.T) {
+	var current atomic.Value
+	current.Store(uAppID + ":" + uAppKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credentials := strings.SplitN(current.Load().(string), ":", 2)
+		if _, err := VerifyRequest(r, func(appID string) (string, error) {
+			if appID != credentials[0] {
+				return "", errors.New("unknown app id")
+			}
+			return credentials[1], nil
+		}, 0); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	endpoints := WithEndpoints(Endpoint{Host: u.Hostname(), Port: port})
+	healthCheck := WithHealthCheck(HealthCheck{Interval: 5 * time.Millisecond})
+	staysHealthy := func(client func() *Client) {
+		deadline := time.Now().Add(2 * time.Second)
+		for !client().Endpoints()[0].Healthy || client().Endpoints()[0].LastChecked.IsZero() {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the endpoint to become healthy, got %+v", client().Endpoints())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if status := client().Endpoints()[0]; !status.Healthy {
+			t.Errorf("expected probes to use the current credentials, got %+v", status)
+		}
+	}
+
+	client, err := NewClientWithOptions(uAppID, uAppKey, u.Hostname(), port, uRealm, false, false, endpoints, healthCheck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	staysHealthy(func() *Client { return client })
+	if err := client.PromoteKey("abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	current.Store(uAppID + ":abcdef")
+	if err := client.RetireKey(uAppKey); err != nil {
+		t.Fatal(err)
+	}
+	staysHealthy(func() *Client { return client })
+
+	ssl := false
+	registry := NewRegistry()
+	cfg := Config{AppID: uAppID, AppKey: "abcdef", Host: u.Hostname(), Port: port, Realm: uRealm, SSL: &ssl}
+	if err := registry.Register(context.Background(), "retail", cfg, nil, endpoints, healthCheck); err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Remove("retail")
+	tenant := func() *Client {
+		client, err := registry.Client("retail")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	staysHealthy(tenant)
+	current.Store("rotated:" + uAppKey)
+	if err := registry.UpdateCredentials("retail", "rotated", uAppKey); err != nil {
+		t.Fatal(err)
+	}
+	staysHealthy(tenant)
+}
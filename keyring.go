@@ -0,0 +1,197 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"sync"
+
+	validators "github.com/secureauthcorp/saidp-sdk-go/utilities/validators"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrUnknownKey : returned when promoting or retiring a key that is not active on the client.
+var ErrUnknownKey = errors.New("saidp: AppKey is not active")
+
+// keyRing :
+//	non-exportable ordered set of active AppKeys shared by all copies of a client. The first key is the primary
+//	one used for signing; every key is accepted when verifying response signatures. A copy of the client whose
+//	AppKey field is set to another key than owner is detached from the ring and only uses its AppKey.
+type keyRing struct {
+	mu    sync.RWMutex
+	owner string
+	keys  []string
+}
+
+// newKeyRing :
+//	non-exportable helper creating the key ring of a client built with appKey.
+func newKeyRing(appKey string) *keyRing {
+	return &keyRing{owner: appKey, keys: []string{appKey}}
+}
+
+// WithAppKeys :
+//	Option to accept response signatures made with additional AppKeys, e.g. the previous key while a rotation
+//	is in progress. Requests are still signed with the primary AppKey.
+// Parameters:
+//	[Required] keys: one or more hex encoded AppKeys.
+func WithAppKeys(keys ...string) ClientOption {
+	return func(c *Client) error {
+		for _, key := range keys {
+			if err := c.AddKey(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AddKey :
+//	Function adding an AppKey to the active keys as the lowest priority key. It is accepted for response
+//	signatures immediately, and used for signing once promoted.
+// Parameters:
+//	[Required] key: the hex encoded AppKey.
+// Returns:
+//	Error: ErrInvalidAppKey when the key is not hex encoded.
+func (c Client) AddKey(key string) error {
+	if !validators.ValidateAppKey(key) {
+		return ErrInvalidAppKey
+	}
+	ring, err := c.keyRing()
+	if err != nil {
+		return err
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if indexOf(ring.keys, key) < 0 {
+		ring.keys = append(ring.keys, key)
+	}
+	return nil
+}
+
+// PromoteKey :
+//	Function making an AppKey the primary key used to sign requests, adding it when it is not active yet. The
+//	previous primary key stays active for response signatures until it is retired. The AppKey field keeps the
+//	key the client was built with; use ActiveKeys to read the current primary key.
+// Parameters:
+//	[Required] key: the hex encoded AppKey.
+// Returns:
+//	Error: ErrInvalidAppKey when the key is not hex encoded.
+func (c Client) PromoteKey(key string) error {
+	if !validators.ValidateAppKey(key) {
+		return ErrInvalidAppKey
+	}
+	ring, err := c.keyRing()
+	if err != nil {
+		return err
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	keys := []string{key}
+	for _, active := range ring.keys {
+		if active != key {
+			keys = append(keys, active)
+		}
+	}
+	ring.keys = keys
+	return nil
+}
+
+// RetireKey :
+//	Function removing an AppKey from the active keys. When the primary key is retired the next key is promoted.
+//	The last active key cannot be retired.
+// Parameters:
+//	[Required] key: the AppKey to retire.
+// Returns:
+//	Error: ErrUnknownKey when the key is not active, or an error when it is the last active key.
+func (c Client) RetireKey(key string) error {
+	ring, err := c.keyRing()
+	if err != nil {
+		return err
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	i := indexOf(ring.keys, key)
+	if i < 0 {
+		return ErrUnknownKey
+	}
+	if len(ring.keys) == 1 {
+		return errors.New("The last active AppKey cannot be retired")
+	}
+	ring.keys = append(ring.keys[:i:i], ring.keys[i+1:]...)
+	return nil
+}
+
+// ActiveKeys :
+//	Function returning the active AppKeys, primary key first.
+// Returns:
+//	[]string: copy of the active keys.
+func (c Client) ActiveKeys() []string {
+	if !c.hasKeyRing() {
+		return []string{c.AppKey}
+	}
+	c.keys.mu.RLock()
+	defer c.keys.mu.RUnlock()
+	return append([]string(nil), c.keys.keys...)
+}
+
+// primaryKey :
+//	non-exportable helper returning the key used to sign requests. Clients declared as struct literals, or whose
+//	AppKey was overwritten, sign with AppKey.
+func (c Client) primaryKey() string {
+	if !c.hasKeyRing() {
+		return c.AppKey
+	}
+	c.keys.mu.RLock()
+	defer c.keys.mu.RUnlock()
+	return c.keys.keys[0]
+}
+
+// keyRing :
+//	non-exportable helper returning the key ring of clients built with NewClient.
+func (c Client) keyRing() (*keyRing, error) {
+	if !c.hasKeyRing() {
+		return nil, errors.New("Key rotation requires a client built with NewClient or NewClientWithOptions")
+	}
+	return c.keys, nil
+}
+
+// hasKeyRing :
+//	non-exportable helper reporting whether the client uses its key ring.
+func (c Client) hasKeyRing() bool {
+	return c.keys != nil && c.keys.owner == c.AppKey
+}
+
+// indexOf :
+//	non-exportable helper returning the position of key in keys, or -1.
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
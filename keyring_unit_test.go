@@ -0,0 +1,107 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestKeyRotation_Unit(t *testing.T) {
+	const newKey = "abcdef"
+	var signWith atomic.Value
+	signWith.Store(uAppKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"status":"found","message":""}`
+		timestamp := r.Header.Get("X-SA-Date")
+		sig, _ := makeHmac(timestamp+"\n"+uAppID+"\n"+body, signWith.Load().(string))
+		w.Header().Set("X-SA-Date", timestamp)
+		w.Header().Set("X-SA-Signature", sig)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server, WithSignatureVerification(0))
+	call := func() error {
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	signWith.Store(newKey)
+	if err := call(); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature before the new key is active, got %v", err)
+	}
+	if err := client.AddKey("zz"); !errors.Is(err, ErrInvalidAppKey) {
+		t.Errorf("expected ErrInvalidAppKey, got %v", err)
+	}
+	if err := client.AddKey(newKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(); err != nil {
+		t.Fatalf("expected responses signed with the secondary key to be accepted: %v", err)
+	}
+	if err := client.PromoteKey(newKey); err != nil {
+		t.Fatal(err)
+	}
+	if keys := client.ActiveKeys(); len(keys) != 2 || keys[0] != newKey || keys[1] != uAppKey {
+		t.Errorf("unexpected active keys %v", keys)
+	}
+	copied := *client
+	req, err := copied.BuildGetRequest("/api/v1/users/" + uUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyRequest(req, func(string) (string, error) { return newKey, nil }, 0); err != nil {
+		t.Errorf("expected copies of the client to sign with the promoted key: %v", err)
+	}
+	signWith.Store(uAppKey)
+	if err := call(); err != nil {
+		t.Fatalf("expected responses signed with the old key to be accepted until retired: %v", err)
+	}
+	if err := client.RetireKey(uAppKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature after retiring the old key, got %v", err)
+	}
+	if err := client.RetireKey(uAppKey); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	if err := client.RetireKey(newKey); err == nil {
+		t.Error("expected retiring the last key to fail")
+	}
+}
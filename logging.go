@@ -0,0 +1,228 @@
+package saidp_sdk_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Redacted : placeholder written in logs instead of a secret value.
+const Redacted = "[REDACTED]"
+
+// sensitiveFields : lower cased JSON fields whose values never reach a log record. Covers passwords
+// (password, currentPassword, newPassword), tokens and OTPs, KBA answers, the profile pinHash and the oath seed key.
+var sensitiveFields = map[string]bool{
+	"password":        true,
+	"currentpassword": true,
+	"newpassword":     true,
+	"token":           true,
+	"otp":             true,
+	"answer":          true,
+	"pinhash":         true,
+	"key":             true,
+}
+
+// sensitiveHeaders : canonical header names whose values never reach a log record.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// WithLogger :
+//	Option to log every API call made by the client with a structured logger. Successful calls are logged at
+//	Info, non 200 responses at Warn and transport errors at Error with the method, endpoint, realm, status,
+//	duration and the SecureAuth status/message. When the logger has Debug enabled the headers and bodies are
+//	logged as well, with secrets redacted.
+// Parameters:
+//	[Required] logger: the slog logger to write to.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) error {
+		if logger == nil {
+			return errors.New("A non-nil logger is required")
+		}
+		c.middleware = append(c.middleware, LoggingMiddleware(logger))
+		return nil
+	}
+}
+
+// LoggingMiddleware :
+//	Function building the Middleware used by WithLogger, for callers composing their own middleware chain.
+// Parameters:
+//	[Required] logger: the slog logger to write to.
+// Returns:
+//	Middleware: middleware logging every attempt.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+			resp, err := next(req)
+			duration := time.Since(start)
+
+			realm, endpoint := splitRealm(req.URL.EscapedPath())
+			level := slog.LevelInfo
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("endpoint", endpoint),
+				slog.String("realm", realm),
+				slog.Int("attempt", Attempt(req)),
+				slog.Duration("duration", duration),
+			}
+			if err != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("error", err.Error()))
+			} else {
+				if resp.StatusCode != http.StatusOK {
+					level = slog.LevelWarn
+				}
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+			if !logger.Enabled(ctx, level) {
+				return resp, err
+			}
+			debug := logger.Enabled(ctx, slog.LevelDebug)
+
+			if saStatus, saMessage := ResponseStatus(resp); saStatus != "" {
+				attrs = append(attrs, slog.String("sa_status", saStatus), slog.String("sa_message", saMessage))
+			}
+			if debug {
+				attrs = append(attrs, slog.Any("request_headers", RedactHeaders(req.Header)))
+				if req.GetBody != nil {
+					if body, bodyErr := req.GetBody(); bodyErr == nil {
+						content, _ := io.ReadAll(body)
+						body.Close()
+						attrs = append(attrs, slog.String("request_body", string(RedactJSON(content))))
+					}
+				}
+				if resp != nil && resp.Body != nil {
+					var respBody []byte
+					respBody, resp.Body = peekBody(resp.Body)
+					attrs = append(attrs, slog.String("response_body", string(RedactJSON(respBody))))
+				}
+			}
+			logger.LogAttrs(ctx, level, "secureauth api call", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// RedactJSON :
+//	Helper function returning a copy of a JSON document with the values of sensitive fields replaced by Redacted,
+//	at any depth. Documents that are not valid JSON are replaced entirely since their content cannot be inspected.
+// Parameters:
+//	[Required] body: the JSON document.
+// Returns:
+//	[]byte: the redacted document.
+func RedactJSON(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return []byte(Redacted)
+	}
+	redacted, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return redacted
+}
+
+// RedactHeaders :
+//	Helper function returning a copy of the headers with credentials such as Authorization replaced by Redacted.
+// Parameters:
+//	[Required] header: the headers to copy.
+// Returns:
+//	http.Header: the redacted copy.
+func RedactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{Redacted}
+		}
+	}
+	return redacted
+}
+
+// redactValue :
+//	non-exportable helper walking a decoded JSON value and redacting sensitive fields in place.
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if sensitiveFields[strings.ToLower(k)] {
+				value[k] = Redacted
+				continue
+			}
+			value[k] = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redactValue(child)
+		}
+	}
+	return v
+}
+
+// peekBody :
+//	non-exportable helper reading a response body and returning its content with a replacement body
+//	so the caller can still consume it.
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	content, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return content, io.NopCloser(io.MultiReader(bytes.NewReader(content), errReader{err}))
+	}
+	return content, io.NopCloser(bytes.NewReader(content))
+}
+
+// errReader : reader replaying a read error after a peeked body.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// splitRealm :
+//	non-exportable helper splitting a request path into the realm and the endpoint after it.
+func splitRealm(path string) (string, string) {
+	trimmed := strings.TrimPrefix(path, "/")
+	if i := strings.Index(trimmed, "/"); i >= 0 {
+		return trimmed[:i], trimmed[i:]
+	}
+	return trimmed, ""
+}
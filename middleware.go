@@ -0,0 +1,232 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// attemptKey : context key holding the attempt number of a request.
+type attemptKey struct{}
+
+// RoundTripFunc :
+//	Function executing a signed request, the unit wrapped by Middleware.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware :
+//	Interceptor wrapping every attempt made by a client. It receives the signed request and may add headers
+//	(signatures do not cover headers), observe the response, latency and transport error, or short circuit the call.
+//	Non 200 responses are seen as responses; they are turned into *HTTPError after the middleware chain. The same
+//	type wraps whole calls when registered with WithCallMiddleware.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// CallInfo :
+//	Outcome of a single attempt, passed to Hooks.After.
+// Fields:
+//	Request: the signed request that was sent.
+//	Response: the http response, nil when Err is set. The body must not be consumed by hooks.
+//	Err: the transport error, if any.
+//	Latency: time spent executing the attempt.
+//	Attempt: attempt number, starting at 1 (see RetryPolicy).
+type CallInfo struct {
+	Request  *http.Request
+	Response *http.Response
+	Err      error
+	Latency  time.Duration
+	Attempt  int
+}
+
+// Hooks :
+//	Before/After callbacks run around every attempt, a simpler alternative to writing a Middleware.
+// Fields:
+//	Before: called with the signed request before it is sent, e.g. to add a correlation id header.
+//	After: called with the outcome of the attempt, e.g. for audit logging.
+type Hooks struct {
+	Before func(req *http.Request)
+	After  func(info CallInfo)
+}
+
+// WithMiddleware :
+//	Option to register middleware on the client. The first middleware registered is the outermost one.
+// Parameters:
+//	[Required] mw: one or more middleware.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("A non-nil middleware is required")
+			}
+		}
+		c.middleware = append(c.middleware, mw...)
+		return nil
+	}
+}
+
+// WithCallMiddleware :
+//	Option to register middleware wrapping a whole call, once, around its retries and failover. It sees the
+//	request before it is sent the first time and the final outcome of DoContext: the 200 response, or the error
+//	returned to the caller (*HTTPError for non 200 responses). Attempt returns 0 at this level. The first
+//	middleware registered is the outermost one.
+// Parameters:
+//	[Required] mw: one or more middleware.
+func WithCallMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("A non-nil middleware is required")
+			}
+		}
+		c.callMiddleware = append(c.callMiddleware, mw...)
+		return nil
+	}
+}
+
+// WithHooks :
+//	Option to register Before/After hooks on the client.
+// Parameters:
+//	[Required] hooks: the callbacks to run around every attempt.
+func WithHooks(hooks Hooks) ClientOption {
+	return WithMiddleware(hooks.Middleware())
+}
+
+// Middleware :
+//	Function converting the hooks to a Middleware.
+// Returns:
+//	Middleware: middleware calling Before and After around the next round trip.
+func (h Hooks) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if h.Before != nil {
+				h.Before(req)
+			}
+			start := time.Now()
+			resp, err := next(req)
+			if h.After != nil {
+				h.After(CallInfo{Request: req, Response: resp, Err: err, Latency: time.Since(start), Attempt: Attempt(req)})
+			}
+			return resp, err
+		}
+	}
+}
+
+// Attempt :
+//	Helper function returning the attempt number of a request seen by a Middleware.
+// Parameters:
+//	[Required] req: the request passed to the middleware.
+// Returns:
+//	int: attempt number starting at 1, or 0 when the request was not sent by a client.
+func Attempt(req *http.Request) int {
+	attempt, _ := req.Context().Value(attemptKey{}).(int)
+	return attempt
+}
+
+// EndpointTemplate :
+//	Helper function replacing the user, group and reference ids of an endpoint with placeholders, e.g.
+//	/api/v1/users/jsmith/factors becomes /api/v1/users/{user}/factors. Useful as a low cardinality label
+//	that keeps user ids out of traces and metrics.
+// Parameters:
+//	[Required] endpoint: the api endpoint after the realm, or a request path including the realm.
+// Returns:
+//	string: the endpoint template.
+func EndpointTemplate(endpoint string) string {
+	if !strings.HasPrefix(endpoint, "/api/") {
+		_, endpoint = splitRealm(endpoint)
+	}
+	segments := strings.Split(endpoint, "/")
+	for i := 1; i < len(segments); i++ {
+		if segments[i] == "" {
+			continue
+		}
+		switch segments[i-1] {
+		case "users":
+			segments[i] = "{user}"
+		case "groups":
+			segments[i] = "{group}"
+		case "auth":
+			segments[i] = "{ref_id}"
+		case "factors":
+			segments[i] = "{factor}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// ResponseStatus :
+//	Helper function returning the SecureAuth status and message of a response seen by a Middleware.
+//	The body is buffered and replaced so it can still be read by the client.
+// Parameters:
+//	[Required] resp: the http response.
+// Returns:
+//	string: the SecureAuth status, empty when the body is not a SecureAuth JSON document.
+//	string: the SecureAuth message.
+func ResponseStatus(resp *http.Response) (string, string) {
+	if resp == nil || resp.Body == nil {
+		return "", ""
+	}
+	var content []byte
+	content, resp.Body = peekBody(resp.Body)
+	var saStatus struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(content, &saStatus); err != nil {
+		return "", ""
+	}
+	return saStatus.Status, saStatus.Message
+}
+
+// roundTripper :
+//	non-exportable helper composing the client middleware around the http client.
+func (c Client) roundTripper(httpClient *http.Client) RoundTripFunc {
+	rt := RoundTripFunc(httpClient.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt
+}
+
+// caller :
+//	non-exportable helper composing the client call middleware around the attempts of a call.
+func (c Client) caller() RoundTripFunc {
+	call := RoundTripFunc(c.doAttempts)
+	for i := len(c.callMiddleware) - 1; i >= 0; i-- {
+		call = c.callMiddleware[i](call)
+	}
+	return call
+}
+
+// withAttempt :
+//	non-exportable helper tagging a request with its attempt number.
+func withAttempt(req *http.Request, attempt int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), attemptKey{}, attempt))
+}
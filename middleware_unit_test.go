@@ -0,0 +1,142 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestMiddleware_Unit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Correlation-ID") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	var order []string
+	var infos []CallInfo
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(req)
+			}
+		}
+	}
+	hooks := Hooks{
+		Before: func(req *http.Request) { req.Header.Set("X-Correlation-ID", "abc") },
+		After:  func(info CallInfo) { infos = append(infos, info) },
+	}
+	client := newTestClient(t, server, WithMiddleware(trace("outer"), trace("inner")), WithHooks(hooks))
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("unexpected middleware order: %v", order)
+	}
+	if len(infos) != 1 || infos[0].Attempt != 1 || infos[0].Response.StatusCode != 200 || infos[0].Latency <= 0 {
+		t.Errorf("unexpected call info: %+v", infos)
+	}
+	if infos[0].Request.Header.Get("Authorization") == "" {
+		t.Error("expected hooks to see the signed request")
+	}
+}
+
+func TestCallMiddleware_Unit(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":"not_found","message":"User not found"}`))
+	}))
+	defer server.Close()
+	var calls, attempts []int
+	var outcome error
+	call := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, Attempt(req))
+			resp, err := next(req)
+			outcome = err
+			return resp, err
+		}
+	}
+	hooks := Hooks{After: func(info CallInfo) { attempts = append(attempts, info.Attempt) }}
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client := newTestClient(t, server, WithRetryPolicy(policy), WithCallMiddleware(call), WithHooks(hooks))
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+		t.Fatalf("expected a 404 *HTTPError, got %v", err)
+	}
+	if len(calls) != 1 || calls[0] != 0 || len(attempts) != 3 {
+		t.Errorf("expected one call around three attempts, got calls %v and attempts %v", calls, attempts)
+	}
+	if outcome != err {
+		t.Errorf("expected the call middleware to see the final error, got %v", outcome)
+	}
+}
+
+func TestEndpointTemplate_Unit(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/users/jsmith/factors":                "/api/v1/users/{user}/factors",
+		"/secureauth1/api/v1/users/jsmith/throttle":   "/api/v1/users/{user}/throttle",
+		"/api/v1/users/jsmith/groups/Admins":          "/api/v1/users/{user}/groups/{group}",
+		"/api/v1/groups/Admins/users":                 "/api/v1/groups/{group}/users",
+		"/api/v1/auth/5ca2d8e1-2a1c-4e63-b6a5-1a2b3c": "/api/v1/auth/{ref_id}",
+		"/api/v1/auth":                        "/api/v1/auth",
+		"/api/v1/users/jsmith/factors/Phone1": "/api/v1/users/{user}/factors/{factor}",
+		"/api/v1/users/":                      "/api/v1/users/",
+	}
+	for in, want := range cases {
+		if got := EndpointTemplate(in); got != want {
+			t.Errorf("EndpointTemplate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
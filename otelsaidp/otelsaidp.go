@@ -0,0 +1,344 @@
+// Package otelsaidp instruments a saidp_sdk_go Client with OpenTelemetry traces and metrics.
+package otelsaidp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// instrumentationName : name of the tracer and meter created by this package.
+const instrumentationName = "github.com/secureauthcorp/saidp-sdk-go/otelsaidp"
+
+// Attribute keys set on spans and metrics.
+const (
+	EndpointKey = attribute.Key("saidp.endpoint")
+	RealmKey    = attribute.Key("saidp.realm")
+	AuthTypeKey = attribute.Key("saidp.auth_type")
+	StatusKey   = attribute.Key("saidp.status")
+	AttemptKey  = attribute.Key("saidp.attempt")
+	UserIDKey   = attribute.Key("saidp.user_id")
+	MethodKey   = attribute.Key("http.request.method")
+	HTTPCodeKey = attribute.Key("http.response.status_code")
+	ErrorKey    = attribute.Key("error.type")
+)
+
+// config : settings collected from the Option list.
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+	userIDs        bool
+}
+
+// Option :
+//	Functional option used to configure the instrumentation.
+type Option func(*config)
+
+// WithTracerProvider :
+//	Option to use a specific tracer provider instead of the global one.
+// Parameters:
+//	[Required] tp: the tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider :
+//	Option to use a specific meter provider instead of the global one.
+// Parameters:
+//	[Required] mp: the meter provider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators :
+//	Option to use specific propagators, instead of the global ones, to inject the trace context into request headers.
+// Parameters:
+//	[Required] p: the propagators.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// WithUserIDs :
+//	Option to record the user id of a call as a span attribute. User ids are personal data and are kept out of
+//	spans by default; metrics never carry them.
+func WithUserIDs() Option {
+	return func(c *config) {
+		c.userIDs = true
+	}
+}
+
+// Instrument :
+//	Function returning a client option that instruments every API call made by the client: one call span with
+//	the call metrics (see CallMiddleware) and a child span per attempt (see Middleware).
+// Parameters:
+//	opts: instrumentation options.
+// Returns:
+//	sa.ClientOption: option for sa.NewClientWithOptions.
+func Instrument(opts ...Option) sa.ClientOption {
+	return func(c *sa.Client) error {
+		inst, err := newInstrumentation(opts)
+		if err != nil {
+			return err
+		}
+		if err := sa.WithCallMiddleware(inst.call())(c); err != nil {
+			return err
+		}
+		return sa.WithMiddleware(inst.attempt())(c)
+	}
+}
+
+// CallMiddleware :
+//	Function building the call instrumentation, to register with sa.WithCallMiddleware. Every call gets a client
+//	span named after the endpoint template, wrapping its retries and failover, and is recorded once in the
+//	saidp.client.duration histogram (seconds); calls ending with an error also increment the saidp.client.errors
+//	counter once.
+// Parameters:
+//	opts: instrumentation options.
+// Returns:
+//	sa.Middleware: the call instrumentation middleware.
+//	Error: If the instruments cannot be created.
+func CallMiddleware(opts ...Option) (sa.Middleware, error) {
+	inst, err := newInstrumentation(opts)
+	if err != nil {
+		return nil, err
+	}
+	return inst.call(), nil
+}
+
+// Middleware :
+//	Function building the attempt instrumentation, to register with sa.WithMiddleware. Every attempt gets a client
+//	span, child of the call span started by CallMiddleware, and its trace context is injected into the request
+//	headers. Metrics are recorded per call by CallMiddleware only.
+// Parameters:
+//	opts: instrumentation options.
+// Returns:
+//	sa.Middleware: the attempt instrumentation middleware.
+//	Error: If the instruments cannot be created.
+func Middleware(opts ...Option) (sa.Middleware, error) {
+	inst, err := newInstrumentation(opts)
+	if err != nil {
+		return nil, err
+	}
+	return inst.attempt(), nil
+}
+
+// instrumentation : tracer, instruments and settings shared by the call and attempt middleware.
+type instrumentation struct {
+	cfg        config
+	tracer     trace.Tracer
+	duration   metric.Float64Histogram
+	errorCount metric.Int64Counter
+}
+
+// newInstrumentation :
+//	non-exportable helper applying the options and creating the tracer and instruments.
+func newInstrumentation(opts []Option) (*instrumentation, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("saidp.client.duration",
+		metric.WithDescription("Duration of SecureAuth API calls, retries included."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	errorCount, err := meter.Int64Counter("saidp.client.errors",
+		metric.WithDescription("Number of SecureAuth API calls ending with an error."), metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentation{
+		cfg:        cfg,
+		tracer:     cfg.tracerProvider.Tracer(instrumentationName),
+		duration:   duration,
+		errorCount: errorCount,
+	}, nil
+}
+
+// call :
+//	non-exportable helper building the middleware tracing and measuring whole calls.
+func (i *instrumentation) call() sa.Middleware {
+	return func(next sa.RoundTripFunc) sa.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			name, attrs, spanAttrs := i.requestAttributes(req)
+			ctx, span := i.tracer.Start(req.Context(), name,
+				trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttrs...))
+			defer span.End()
+
+			start := time.Now()
+			resp, err := next(req.WithContext(ctx))
+			elapsed := time.Since(start).Seconds()
+
+			var httpErr *sa.HTTPError
+			switch {
+			case errors.As(err, &httpErr):
+				attrs = append(attrs, HTTPCodeKey.Int(httpErr.Code))
+				span.SetAttributes(HTTPCodeKey.Int(httpErr.Code))
+				if httpErr.Status != "" {
+					attrs = append(attrs, StatusKey.String(httpErr.Status))
+					span.SetAttributes(StatusKey.String(httpErr.Status))
+				}
+				span.SetStatus(codes.Error, http.StatusText(httpErr.Code))
+				attrs = append(attrs, ErrorKey.String(strconv.Itoa(httpErr.Code)))
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				attrs = append(attrs, ErrorKey.String(errorType(err)))
+			default:
+				attrs = append(attrs, HTTPCodeKey.Int(resp.StatusCode))
+				span.SetAttributes(HTTPCodeKey.Int(resp.StatusCode))
+				if status, _ := sa.ResponseStatus(resp); status != "" {
+					attrs = append(attrs, StatusKey.String(status))
+					span.SetAttributes(StatusKey.String(status))
+				}
+			}
+			if err != nil {
+				i.errorCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+			i.duration.Record(ctx, elapsed, metric.WithAttributes(attrs...))
+			return resp, err
+		}
+	}
+}
+
+// attempt :
+//	non-exportable helper building the middleware tracing each attempt as a child of the call span.
+func (i *instrumentation) attempt() sa.Middleware {
+	return func(next sa.RoundTripFunc) sa.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			name, _, spanAttrs := i.requestAttributes(req)
+			spanAttrs = append(spanAttrs, AttemptKey.Int(sa.Attempt(req)))
+			ctx, span := i.tracer.Start(req.Context(), name+" attempt",
+				trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttrs...))
+			defer span.End()
+			req = req.WithContext(ctx)
+			i.cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+			span.SetAttributes(HTTPCodeKey.Int(resp.StatusCode))
+			if status, _ := sa.ResponseStatus(resp); status != "" {
+				span.SetAttributes(StatusKey.String(status))
+			}
+			if resp.StatusCode != http.StatusOK {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			return resp, nil
+		}
+	}
+}
+
+// requestAttributes :
+//	non-exportable helper returning the span name, the metric attributes and the span attributes of a request.
+func (i *instrumentation) requestAttributes(req *http.Request) (string, []attribute.KeyValue, []attribute.KeyValue) {
+	realm := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)[0]
+	endpoint := sa.EndpointTemplate(req.URL.EscapedPath())
+	attrs := []attribute.KeyValue{
+		EndpointKey.String(endpoint),
+		RealmKey.String(realm),
+		MethodKey.String(req.Method),
+	}
+	authType, userID := requestFields(req)
+	if authType != "" {
+		attrs = append(attrs, AuthTypeKey.String(authType))
+	}
+	spanAttrs := append([]attribute.KeyValue(nil), attrs...)
+	if i.cfg.userIDs && userID != "" {
+		spanAttrs = append(spanAttrs, UserIDKey.String(userID))
+	}
+	return "SecureAuth " + req.Method + " " + endpoint, attrs, spanAttrs
+}
+
+// requestFields :
+//	non-exportable helper reading the auth type and user id from a request body, if any.
+func requestFields(req *http.Request) (string, string) {
+	if req.GetBody == nil {
+		return "", ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", ""
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil || !bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		return "", ""
+	}
+	var fields struct {
+		Type   string `json:"type"`
+		UserID string `json:"user_id"`
+	}
+	json.Unmarshal(content, &fields)
+	return fields.Type, fields.UserID
+}
+
+// errorType :
+//	non-exportable helper classifying a transport error with a low cardinality value.
+func errorType(err error) string {
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeout) && timeout.Timeout():
+		return "timeout"
+	default:
+		return "transport"
+	}
+}
@@ -0,0 +1,201 @@
+package otelsaidp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+const (
+	uAppID  = "12345"
+	uAppKey = "123456"
+	uRealm  = "secureauth1"
+	uUser   = "user"
+)
+
+func TestInstrument_Unit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	client, err := sa.NewClientWithOptions(uAppID, uAppKey, u.Hostname(), port, uRealm, false, false,
+		Instrument(WithTracerProvider(tp), WithMeterProvider(mp), WithPropagators(propagation.TraceContext{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := client.BuildPostRequest("/api/v1/auth", `{"user_id":"`+uUser+`","type":"password","token":"secret"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected a call span and an attempt span, got %d", len(ended))
+	}
+	attempt, call := ended[0], ended[1]
+	if attempt.Parent().SpanID() != call.SpanContext().SpanID() || attempt.Name() != "SecureAuth POST /api/v1/auth attempt" {
+		t.Errorf("expected the attempt span %q to be a child of the call span", attempt.Name())
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range call.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	if call.Name() != "SecureAuth POST /api/v1/auth" || got[EndpointKey].AsString() != "/api/v1/auth" ||
+		got[AuthTypeKey].AsString() != "password" || got[RealmKey].AsString() != uRealm ||
+		got[HTTPCodeKey].AsInt64() != 200 || got[StatusKey].AsString() != "found" {
+		t.Errorf("unexpected span %q: %v", call.Name(), got)
+	}
+	if _, ok := got[UserIDKey]; ok {
+		t.Error("user ids must not be recorded by default")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var recorded bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if h, ok := m.Data.(metricdata.Histogram[float64]); ok && m.Name == "saidp.client.duration" {
+				recorded = len(h.DataPoints) == 1 && h.DataPoints[0].Count == 1
+			}
+			if m.Name == "saidp.client.errors" {
+				t.Error("no error should be counted for a successful call")
+			}
+		}
+	}
+	if !recorded {
+		t.Error("expected the call duration to be recorded")
+	}
+}
+
+func TestInstrumentRetries_Unit(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the second attempt of the first call succeeds.
+		if atomic.AddInt32(&hits, 1) != 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	policy := sa.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxAttempts = 2
+	client, err := sa.NewClientWithOptions(uAppID, uAppKey, u.Hostname(), port, uRealm, false, false,
+		sa.WithRetryPolicy(policy), Instrument(WithTracerProvider(tp), WithMeterProvider(mp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if i == 0 {
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		}
+	}
+
+	var calls, attempts int
+	for _, span := range spans.Ended() {
+		if span.Parent().IsValid() {
+			attempts++
+		} else {
+			calls++
+		}
+	}
+	if calls != 2 || attempts != 4 {
+		t.Errorf("expected 2 call spans and 4 attempt spans, got %d and %d", calls, attempts)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var durations, errs int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					durations += int64(dp.Count)
+				}
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					errs += dp.Value
+				}
+			}
+		}
+	}
+	if durations != 2 || errs != 1 {
+		t.Errorf("expected 2 call durations and 1 failed call, got %d and %d", durations, errs)
+	}
+}
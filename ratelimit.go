@@ -0,0 +1,246 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// LimiterStats :
+//	Snapshot of the client side rate limiter and concurrency cap, used to tune them per realm.
+// Fields:
+//	Acquired: number of attempts admitted by the limiter.
+//	Waited: number of admitted attempts that had to wait for a token or a free slot.
+//	TotalWait: accumulated time spent waiting.
+//	MaxWait: longest single wait.
+//	Canceled: number of attempts abandoned because their context was done while waiting.
+//	InFlight: number of attempts currently holding a slot.
+type LimiterStats struct {
+	Acquired  uint64
+	Waited    uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	Canceled  uint64
+	InFlight  int
+}
+
+// limiter :
+//	non-exportable token bucket and in-flight semaphore applied to every attempt made by a client.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	slots  chan struct{}
+	stats  LimiterStats
+}
+
+// WithRateLimit :
+//	Option to cap the rate of calls made by the client with a token bucket. Calls over the rate wait for a token
+//	(or until their context is done) instead of being sent to the SecureAuth web service.
+// Parameters:
+//	[Required] perSecond: sustained number of calls per second.
+//	[Required] burst: number of calls that can be made at once before the rate applies.
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(c *Client) error {
+		if perSecond <= 0 || burst < 1 {
+			return errors.New("Rate limit requires a positive rate and a burst of at least 1")
+		}
+		l := c.ensureLimiter()
+		l.rate = perSecond
+		l.burst = float64(burst)
+		l.tokens = float64(burst)
+		return nil
+	}
+}
+
+// WithMaxInFlight :
+//	Option to cap the number of calls the client has in flight at the same time. A call holds its slot until the
+//	body of the returned response is closed, so callers streaming a large body keep counting against the cap.
+// Parameters:
+//	[Required] max: maximum number of concurrent calls.
+func WithMaxInFlight(max int) ClientOption {
+	return func(c *Client) error {
+		if max < 1 {
+			return errors.New("Max in flight must be at least 1")
+		}
+		c.ensureLimiter().slots = make(chan struct{}, max)
+		return nil
+	}
+}
+
+// LimiterStats :
+//	Function returning the current rate limiter and concurrency cap statistics of the client.
+// Returns:
+//	LimiterStats: a snapshot of the statistics; zero when no limit is configured.
+func (c Client) LimiterStats() LimiterStats {
+	if c.limiter == nil {
+		return LimiterStats{}
+	}
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	stats := c.limiter.stats
+	stats.InFlight = len(c.limiter.slots)
+	return stats
+}
+
+// ensureLimiter :
+//	non-exportable helper returning the client limiter, creating it on first use.
+func (c *Client) ensureLimiter() *limiter {
+	if c.limiter == nil {
+		c.limiter = new(limiter)
+	}
+	return c.limiter
+}
+
+// acquire :
+//	non-exportable helper waiting for a token and a free slot. The returned func releases the slot and must be
+//	called once the attempt is done.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	start := time.Now()
+	if err := l.waitToken(ctx); err != nil {
+		l.record(0, err)
+		return nil, err
+	}
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.refund()
+			l.record(0, ctx.Err())
+			return nil, ctx.Err()
+		}
+		release = func() { <-l.slots }
+	}
+	l.record(time.Since(start), nil)
+	return release, nil
+}
+
+// hold :
+//	non-exportable helper tying the release of an in-flight slot to the body of the response. The slot is
+//	released at once when the attempt failed or there is no body to read.
+func (l *limiter) hold(resp *http.Response, release func()) *http.Response {
+	if l == nil || l.slots == nil {
+		return resp
+	}
+	if resp == nil || resp.Body == nil {
+		release()
+		return resp
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: release}
+	return resp
+}
+
+// slotBody :
+//	non-exportable response body releasing its in-flight slot the first time it is closed.
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close :
+//	Function closing the underlying body and releasing the in-flight slot.
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// waitToken :
+//	non-exportable helper reserving a token from the bucket and sleeping until it is available.
+func (l *limiter) waitToken(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+	if deficit <= 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, time.Duration(deficit/l.rate*float64(time.Second))); err != nil {
+		l.refund()
+		return err
+	}
+	return nil
+}
+
+// refund :
+//	non-exportable helper giving a reserved token back to the bucket, so abandoned calls do not slow down the
+//	others.
+func (l *limiter) refund() {
+	if l.rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.mu.Unlock()
+}
+
+// record :
+//	non-exportable helper updating the limiter statistics.
+func (l *limiter) record(wait time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.stats.Canceled++
+		return
+	}
+	l.stats.Acquired++
+	// Anything below a millisecond is scheduling noise rather than throttling.
+	if wait >= time.Millisecond {
+		l.stats.Waited++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestLimiter_Unit(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server, WithRateLimit(100, 2), WithMaxInFlight(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("expected at most 2 calls in flight, got %d", peak)
+	}
+	stats := client.LimiterStats()
+	if stats.Acquired != 8 || stats.Waited == 0 || stats.TotalWait <= 0 || stats.InFlight != 0 {
+		t.Errorf("unexpected limiter stats: %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DoContext(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLimiterHoldsSlotUntilBodyClosed_Unit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server, WithMaxInFlight(1))
+
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := client.LimiterStats(); stats.InFlight != 1 {
+		t.Errorf("expected the slot to be held while the body is open, got %d in flight", stats.InFlight)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err = client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DoContext(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second call to wait for the slot, got %v", err)
+	}
+	resp.Body.Close()
+	resp.Body.Close()
+	if stats := client.LimiterStats(); stats.InFlight != 0 {
+		t.Errorf("expected the slot to be released once the body is closed, got %d in flight", stats.InFlight)
+	}
+
+	req, err = client.BuildGetRequest("/api/v1/users/" + uUser + "/factors?fail=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err == nil {
+		t.Error("expected an error for a 400 response")
+	}
+	if stats := client.LimiterStats(); stats.InFlight != 0 {
+		t.Errorf("expected the slot to be released on error responses, got %d in flight", stats.InFlight)
+	}
+}
+
+func TestLimiterRefundsTokenOnSlotWait_Unit(t *testing.T) {
+	l := &limiter{rate: 1, burst: 1, tokens: 1, slots: make(chan struct{}, 1)}
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.mu.Lock()
+	l.tokens = 1
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the call to time out waiting for the slot, got %v", err)
+	}
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < 1 {
+		t.Errorf("expected the token to be given back when the slot wait is abandoned, got %v", tokens)
+	}
+	release()
+}
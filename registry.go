@@ -0,0 +1,370 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	validators "github.com/secureauthcorp/saidp-sdk-go/utilities/validators"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// UnhealthyAfter : number of consecutive failed calls (transport errors or 5xx responses) after which a realm is
+// reported as unhealthy by a Registry.
+const UnhealthyAfter = 3
+
+// Registry :
+//	Set of named Clients, one per tenant (business unit), each with its own realm and AppID/AppKey. All clients
+//	of a registry share one pooled transport, so tenants on the same host reuse connections. A Registry is safe
+//	for concurrent use and its zero value is not usable; create it with NewRegistry.
+type Registry struct {
+	mu      sync.RWMutex
+	opts    []ClientOption
+	secure  *http.Client
+	bypass  *http.Client
+	tenants map[string]*registryEntry
+}
+
+// registryEntry :
+//	non-exportable registered client, the configuration and options it was built from, and the statistics of its realm.
+type registryEntry struct {
+	client *Client
+	cfg    Config
+	opts   []ClientOption
+	stats  *realmStats
+}
+
+// RealmStats :
+//	Health and call metrics of a tenant's realm, as observed by the registry.
+// Fields:
+//	Tenant: the tenant key.
+//	Realm: the SecureAuth realm of the tenant.
+//	Calls: number of attempts sent.
+//	Errors: number of attempts failing with a transport error or a non 200 response.
+//	ConsecutiveFailures: number of transport errors and 5xx responses since the last successful attempt.
+//	AverageLatency: mean latency of the attempts.
+//	LastError: the last transport error or non 200 status, if any.
+//	LastSuccess: time of the last 200 response.
+//	LastFailure: time of the last failed attempt.
+//	Healthy: false once ConsecutiveFailures reaches UnhealthyAfter.
+//	Limiter: the rate limiter statistics of the tenant's client.
+type RealmStats struct {
+	Tenant              string
+	Realm               string
+	Calls               uint64
+	Errors              uint64
+	ConsecutiveFailures uint64
+	AverageLatency      time.Duration
+	LastError           string
+	LastSuccess         time.Time
+	LastFailure         time.Time
+	Healthy             bool
+	Limiter             LimiterStats
+}
+
+// realmStats :
+//	non-exportable counters updated by the registry middleware of a tenant.
+type realmStats struct {
+	mu                  sync.Mutex
+	calls               uint64
+	errors              uint64
+	consecutiveFailures uint64
+	totalLatency        time.Duration
+	lastError           string
+	lastSuccess         time.Time
+	lastFailure         time.Time
+}
+
+// NewRegistry :
+//	Helper function to create an empty Registry.
+// Parameters:
+//	opts: ClientOption values applied to every client of the registry, before the tenant's own options.
+// Returns:
+//	Registry: the registry.
+func NewRegistry(opts ...ClientOption) *Registry {
+	return &Registry{
+		opts:    opts,
+		secure:  &http.Client{Transport: newTransport(false)},
+		bypass:  &http.Client{Transport: newTransport(true)},
+		tenants: make(map[string]*registryEntry),
+	}
+}
+
+// Register :
+//	Function adding a tenant to the registry. The client is built from cfg like NewClientFromConfig, on the
+//	registry's shared transport. Options changing the http client (WithHTTPClient, WithTransport, WithTLSOptions)
+//	opt the tenant out of the shared pool.
+// Parameters:
+//	[Required] ctx: context bounding the secret lookup.
+//	[Required] tenant: the key calls are routed by.
+//	[Required] cfg: the tenant's configuration.
+//	secrets: provider resolving cfg.AppKeySecret; may be nil when it is not used.
+//	opts: additional options for this tenant.
+// Returns:
+//	Error: If the tenant is already registered or the client cannot be built.
+func (r *Registry) Register(ctx context.Context, tenant string, cfg Config, secrets SecretProvider, opts ...ClientOption) error {
+	if tenant == "" {
+		return errors.New("A tenant key is required")
+	}
+	shared := r.secure
+	if cfg.BypassCertValidation {
+		shared = r.bypass
+	}
+	all := []ClientOption{WithHTTPClient(shared)}
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return fmt.Errorf("Invalid timeout %q: %v", cfg.Timeout, err)
+		}
+		all = append(all, WithTimeout(timeout))
+		cfg.Timeout = ""
+	}
+	stats := new(realmStats)
+	all = append(all, r.opts...)
+	all = append(all, opts...)
+	all = append(all, WithMiddleware(stats.middleware()))
+	client, err := NewClientFromConfig(ctx, cfg, secrets, all...)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[tenant]; ok {
+		client.Close()
+		return fmt.Errorf("Tenant %q is already registered", tenant)
+	}
+	r.tenants[tenant] = &registryEntry{client: client, cfg: cfg, opts: all, stats: stats}
+	return nil
+}
+
+// Remove :
+//	Function removing a tenant from the registry and closing its client. Clients already handed out can still
+//	make calls, but their endpoint health checks are stopped.
+// Parameters:
+//	[Required] tenant: the tenant key.
+func (r *Registry) Remove(tenant string) {
+	r.mu.Lock()
+	entry, ok := r.tenants[tenant]
+	delete(r.tenants, tenant)
+	r.mu.Unlock()
+	if ok {
+		entry.client.Close()
+	}
+}
+
+// Client :
+//	Function returning the current client of a tenant. UpdateCredentials replaces it, so long running callers
+//	should look it up per call or use Do.
+// Parameters:
+//	[Required] tenant: the tenant key.
+// Returns:
+//	Client: the tenant's client.
+//	Error: ErrUnknownTenant when the tenant is not registered.
+func (r *Registry) Client(tenant string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.tenants[tenant]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenant)
+	}
+	return entry.client, nil
+}
+
+// Do :
+//	Function building, signing and executing a call with the current client of a tenant, so the call always
+//	uses the latest credentials (see UpdateCredentials).
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the call.
+//	[Required] tenant: the tenant key.
+//	[Required] method: the http verb of the call (GET, POST, PUT, DELETE, PATCH).
+//	[Required] endpoint: the api endpoint (after the SecureAuth# realm) of the call.
+//	content: the json content of the call.
+// Returns:
+//	Response: the http response, whose body must be closed.
+//	Error: ErrUnknownTenant when the tenant is not registered, or any error returned by DoContext.
+func (r *Registry) Do(ctx context.Context, tenant string, method string, endpoint string, content string) (*http.Response, error) {
+	client, err := r.Client(tenant)
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.BuildRequest(method, endpoint, content)
+	if err != nil {
+		return nil, err
+	}
+	return client.DoContext(ctx, req)
+}
+
+// Tenants :
+//	Function returning the registered tenant keys in sorted order.
+// Returns:
+//	[]string: the tenant keys.
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenants := make([]string, 0, len(r.tenants))
+	for tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// UpdateCredentials :
+//	Function hot-reloading the AppID and AppKey of a tenant, e.g. after a key rotation. A new client is built
+//	from the tenant's configuration and options with the new credentials, and the old client is closed. Calls
+//	started after the update (through Do or a fresh Client lookup) use the new credentials; calls in flight
+//	finish with the old ones. The shared transport and the statistics are kept.
+// Parameters:
+//	[Required] tenant: the tenant key.
+//	[Required] appID: the new SecureAuth API AppId.
+//	[Required] appKey: the new SecureAuth API AppKey, hex encoded.
+// Returns:
+//	Error: ErrUnknownTenant, ErrInvalidAppKey, or an error for a missing AppID.
+func (r *Registry) UpdateCredentials(tenant string, appID string, appKey string) error {
+	if appID == "" {
+		return errors.New("AppID is required for updating credentials")
+	}
+	if !validators.ValidateAppKey(appKey) {
+		return ErrInvalidAppKey
+	}
+	r.mu.Lock()
+	entry, ok := r.tenants[tenant]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrUnknownTenant, tenant)
+	}
+	cfg := entry.cfg
+	cfg.AppID = appID
+	cfg.AppKey = appKey
+	cfg.AppKeyFile = ""
+	cfg.AppKeySecret = ""
+	client, err := NewClientFromConfig(context.Background(), cfg, nil, entry.opts...)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.tenants[tenant] = &registryEntry{client: client, cfg: cfg, opts: entry.opts, stats: entry.stats}
+	r.mu.Unlock()
+	entry.client.Close()
+	return nil
+}
+
+// Stats :
+//	Function returning the health and metrics of a tenant's realm.
+// Parameters:
+//	[Required] tenant: the tenant key.
+// Returns:
+//	RealmStats: snapshot of the realm statistics.
+//	Error: ErrUnknownTenant when the tenant is not registered.
+func (r *Registry) Stats(tenant string) (RealmStats, error) {
+	r.mu.RLock()
+	entry, ok := r.tenants[tenant]
+	r.mu.RUnlock()
+	if !ok {
+		return RealmStats{}, fmt.Errorf("%w: %q", ErrUnknownTenant, tenant)
+	}
+	return entry.snapshot(tenant), nil
+}
+
+// Health :
+//	Function returning the health and metrics of every registered realm, keyed by tenant.
+// Returns:
+//	map[string]RealmStats: snapshot of the statistics of each tenant.
+func (r *Registry) Health() map[string]RealmStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	health := make(map[string]RealmStats, len(r.tenants))
+	for tenant, entry := range r.tenants {
+		health[tenant] = entry.snapshot(tenant)
+	}
+	return health
+}
+
+// snapshot :
+//	non-exportable helper copying the statistics of an entry.
+func (e *registryEntry) snapshot(tenant string) RealmStats {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+	stats := RealmStats{
+		Tenant:              tenant,
+		Realm:               e.client.Realm,
+		Calls:               e.stats.calls,
+		Errors:              e.stats.errors,
+		ConsecutiveFailures: e.stats.consecutiveFailures,
+		LastError:           e.stats.lastError,
+		LastSuccess:         e.stats.lastSuccess,
+		LastFailure:         e.stats.lastFailure,
+		Healthy:             e.stats.consecutiveFailures < UnhealthyAfter,
+		Limiter:             e.client.LimiterStats(),
+	}
+	if e.stats.calls > 0 {
+		stats.AverageLatency = e.stats.totalLatency / time.Duration(e.stats.calls)
+	}
+	return stats
+}
+
+// middleware :
+//	non-exportable helper building the Middleware recording every attempt of a tenant.
+func (s *realmStats) middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			latency := time.Since(start)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.calls++
+			s.totalLatency += latency
+			switch {
+			case err != nil:
+				s.errors++
+				s.consecutiveFailures++
+				s.lastError = err.Error()
+				s.lastFailure = time.Now()
+			case resp.StatusCode != http.StatusOK:
+				s.errors++
+				if resp.StatusCode >= http.StatusInternalServerError {
+					s.consecutiveFailures++
+				}
+				s.lastError = resp.Status
+				s.lastFailure = time.Now()
+			default:
+				s.consecutiveFailures = 0
+				s.lastSuccess = time.Now()
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestRegistry_Unit(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appID, err := VerifyRequest(r, func(appID string) (string, error) {
+			if appID == "rotated" {
+				return "abcdef", nil
+			}
+			return uAppKey, nil
+		}, 0)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/SecureAuth5/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":"` + appID + `"}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	ssl := false
+
+	registry := NewRegistry(WithTimeout(5 * time.Second))
+	for tenant, realm := range map[string]string{"retail": "SecureAuth1", "wholesale": "SecureAuth5"} {
+		cfg := Config{AppID: uAppID, AppKey: uAppKey, Host: u.Hostname(), Port: port, Realm: realm, SSL: &ssl}
+		if err := registry.Register(context.Background(), tenant, cfg, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := registry.Register(context.Background(), "retail", Config{}, nil); err == nil {
+		t.Error("expected a duplicate tenant to fail")
+	}
+	if _, err := registry.Client("unknown"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	if tenants := registry.Tenants(); len(tenants) != 2 || tenants[0] != "retail" {
+		t.Errorf("unexpected tenants %v", tenants)
+	}
+
+	call := func(tenant string) error {
+		client, err := registry.Client(tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		return err
+	}
+	for i := 0; i < UnhealthyAfter; i++ {
+		if err := call("retail"); err != nil {
+			t.Fatal(err)
+		}
+		if err := call("wholesale"); !errors.Is(err, ErrServer) {
+			t.Fatalf("expected ErrServer, got %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected tenants to share one connection, got %d", n)
+	}
+	health := registry.Health()
+	if retail := health["retail"]; !retail.Healthy || retail.Calls != UnhealthyAfter || retail.Errors != 0 || retail.Realm != "SecureAuth1" {
+		t.Errorf("unexpected retail stats %+v", retail)
+	}
+	if wholesale := health["wholesale"]; wholesale.Healthy || wholesale.Errors != UnhealthyAfter || wholesale.LastFailure.IsZero() {
+		t.Errorf("unexpected wholesale stats %+v", wholesale)
+	}
+
+	if err := registry.UpdateCredentials("retail", "rotated", "not-hex"); !errors.Is(err, ErrInvalidAppKey) {
+		t.Errorf("expected ErrInvalidAppKey, got %v", err)
+	}
+	if err := registry.UpdateCredentials("retail", "rotated", "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("retail"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := registry.Do(context.Background(), "retail", http.MethodGet, "/api/v1/users/"+uUser+"/factors", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := registry.Do(context.Background(), "unknown", http.MethodGet, "/api/v1/users/"+uUser+"/factors", ""); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	stats, err := registry.Stats("retail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Calls != UnhealthyAfter+2 || stats.Errors != 0 {
+		t.Errorf("expected stats to survive a credential update, got %+v", stats)
+	}
+	registry.Remove("wholesale")
+	if _, err := registry.Stats("wholesale"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+}
+
+func TestRegistryClientLifecycle_Unit(t *testing.T) {
+	registry := NewRegistry(WithMaxInFlight(4))
+	cfg := Config{AppID: uAppID, AppKey: uAppKey, Host: "idp1.example.com", Realm: uRealm}
+	opts := []ClientOption{
+		WithEndpoints(Endpoint{Host: "idp1.example.com", Port: 443}, Endpoint{Host: "idp2.example.com", Port: 443}),
+		WithHealthCheck(HealthCheck{Interval: time.Hour}),
+	}
+	if err := registry.Register(context.Background(), "retail", cfg, nil, opts...); err != nil {
+		t.Fatal(err)
+	}
+	old, err := registry.Client("retail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.UpdateCredentials("retail", "rotated", "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := registry.Client("retail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AppID != "rotated" || updated.AppKey != "abcdef" || old.AppKey != uAppKey {
+		t.Errorf("expected a new client with the new credentials, got %s/%s", updated.AppID, updated.AppKey)
+	}
+	if updated.hosts == old.hosts || updated.limiter == old.limiter {
+		t.Error("expected the new client not to share endpoint or limiter state with the old one")
+	}
+	if len(updated.hosts.statuses) != 2 {
+		t.Errorf("expected the tenant options to be applied again, got %d endpoints", len(updated.hosts.statuses))
+	}
+	select {
+	case <-old.hosts.stop:
+	default:
+		t.Error("expected the old client to be closed")
+	}
+
+	registry.Remove("retail")
+	select {
+	case <-updated.hosts.stop:
+	default:
+		t.Error("expected a removed tenant's client to be closed")
+	}
+}
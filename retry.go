@@ -0,0 +1,240 @@
+package saidp_sdk_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+var (
+	// validationEndpoints are POST endpoints that only evaluate data and can be safely repeated.
+	validationEndpoints = []string{
+		"/api/v1/otp/validate",
+		"/api/v1/dfp/validate",
+		"/api/v1/dfp/score",
+		"/api/v1/ipeval",
+		"/api/v1/adaptauth",
+	}
+	// validationAuthTypes are auth endpoint types that validate something without delivering anything to the user
+	// or counting towards a lockout. Credential checks (password, kba, oath, pin) count every attempt against the
+	// user's lockout threshold and OTP delivering types (call, sms, email, push, push_accept, help_desk) deliver
+	// again, so neither is retried by default.
+	validationAuthTypes = []string{"user_id"}
+)
+
+// RetryPolicy :
+//	Policy used by Do/DoContext to retry transient failures. Each attempt is signed again since the
+//	Authorization header embeds a timestamp.
+// Fields:
+//	MaxAttempts: total number of attempts, including the first one. Values below 2 disable retries.
+//	InitialBackoff: wait before the second attempt.
+//	MaxBackoff: upper bound for the wait between attempts.
+//	Multiplier: growth factor applied to the wait after every attempt.
+//	Jitter: fraction (0 to 1) of every wait that is randomized to spread retries from many callers.
+//	RetryableStatus: http status codes that are retried. Transient transport errors (timeouts, connection resets
+//	and refusals, truncated responses) are always retried; certificate, pinning and middleware errors never are.
+//	Retryable: decides if a request is safe to repeat. When nil, DefaultRetryable is used.
+type RetryPolicy struct {
+	MaxAttempts     int
+	InitialBackoff  time.Duration
+	MaxBackoff      time.Duration
+	Multiplier      float64
+	Jitter          float64
+	RetryableStatus []int
+	Retryable       func(req *http.Request) bool
+}
+
+// DefaultRetryPolicy :
+//	Helper function returning a policy of 3 attempts with exponential backoff from 200ms up to 2s, 20% jitter,
+//	retrying 502, 503 and 504 responses for requests accepted by DefaultRetryable.
+// Returns:
+//	RetryPolicy: a new policy that can be adjusted before use.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     3,
+		InitialBackoff:  200 * time.Millisecond,
+		MaxBackoff:      2 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		RetryableStatus: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// WithRetryPolicy :
+//	Option to enable automatic retries of transient failures on every call made by the client.
+// Parameters:
+//	[Required] policy: the retry policy, see DefaultRetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if policy == nil {
+			return errors.New("A non-nil retry policy is required")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return errors.New("Retry jitter must be between 0 and 1")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// DefaultRetryable :
+//	Function deciding if a request is safe to repeat. GET requests and validation endpoints (otp validate,
+//	dfp validate/score, ipeval, adaptauth, and auth requests of type user_id) are retryable. Anything that
+//	creates, updates or delivers something, such as sms/call/push auth requests, is not, and neither are
+//	password, kba, oath or pin validations since each attempt counts towards the user's lockout.
+// Parameters:
+//	[Required] req: the signed request about to be retried.
+// Returns:
+//	bool: true if the request can be repeated without side effects.
+func DefaultRetryable(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return true
+	}
+	if req.Method != http.MethodPost {
+		return false
+	}
+	path := req.URL.Path
+	for _, endpoint := range validationEndpoints {
+		if strings.HasSuffix(path, endpoint) {
+			return true
+		}
+	}
+	if !strings.HasSuffix(path, "/api/v1/auth") || req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return false
+	}
+	authRequest := struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal(content, &authRequest); err != nil {
+		return false
+	}
+	for _, t := range validationAuthTypes {
+		if t == authRequest.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// attemptsFor :
+//	non-exportable helper returning how many attempts a request is allowed under the policy.
+func (p *RetryPolicy) attemptsFor(req *http.Request) int {
+	if p == nil || p.MaxAttempts < 2 || !p.retryable(req) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// retryable :
+//	non-exportable helper reporting if a request is safe to repeat, with the Retryable function of the policy, or
+//	DefaultRetryable when there is no policy or function.
+func (p *RetryPolicy) retryable(req *http.Request) bool {
+	if p == nil || p.Retryable == nil {
+		return DefaultRetryable(req)
+	}
+	return p.Retryable(req)
+}
+
+// shouldRetry :
+//	non-exportable helper deciding if the outcome of an attempt is transient.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTransient(err)
+	}
+	for _, status := range p.RetryableStatus {
+		if status == resp.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransient :
+//	non-exportable helper reporting if a transport error is worth another attempt: timeouts, connection resets
+//	and refusals, and responses cut short. Anything else (certificate and pinning failures, middleware errors,
+//	malformed requests) would fail the same way again.
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff :
+//	non-exportable helper computing the wait before the next attempt, with jitter applied.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	wait = wait*(1-p.Jitter) + wait*p.Jitter*rand.Float64()
+	return time.Duration(wait)
+}
+
+// sleepContext :
+//	non-exportable helper waiting for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
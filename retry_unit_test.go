@@ -0,0 +1,179 @@
+package saidp_sdk_go
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestRetryPolicy_Unit(t *testing.T) {
+	var gets, posts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if atomic.AddInt32(&gets, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.Header.Get("Authorization") == "" || r.Header.Get("Date") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"status":"found","message":""}`))
+			return
+		}
+		atomic.AddInt32(&posts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client := newTestClient(t, server, WithRetryPolicy(policy))
+
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if gets != 3 {
+		t.Errorf("expected 3 attempts for a GET, got %d", gets)
+	}
+
+	req, err = client.BuildPostRequest("/api/v1/auth", `{"user_id":"user","type":"sms","factor_id":"Phone1"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); !errors.Is(err, ErrServer) {
+		t.Errorf("expected ErrServer, got %v", err)
+	}
+	if posts != 1 {
+		t.Errorf("expected a single attempt for an sms auth request, got %d", posts)
+	}
+
+	req, err = client.BuildPostRequest("/api/v1/auth", `{"user_id":"user","type":"password","token":"secret"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Do(req)
+	if posts != 2 {
+		t.Errorf("expected a single attempt for a password validation, got %d", posts-1)
+	}
+
+	req, err = client.BuildPostRequest("/api/v1/auth", `{"user_id":"user","type":"user_id"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Do(req)
+	if posts != 5 {
+		t.Errorf("expected 3 attempts for a user_id validation, got %d", posts-2)
+	}
+}
+
+func TestRetrySignsQuery_Unit(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp := r.Header.Get(hdrSADateKey)
+		endpoint := strings.TrimPrefix(r.URL.EscapedPath(), "/"+uRealm) + "?" + r.URL.RawQuery
+		sig, _ := makeHmac(buildAuthPayload(r.Method, timestamp, uAppID, uRealm, endpoint, ""), uAppKey)
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(uAppID+":"+sig))
+		if r.Header.Get(hdrAuthorizationKey) != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&attempts, 1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	defer server.Close()
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client := newTestClient(t, server, WithRetryPolicy(policy))
+
+	req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/throttle?count=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the retried GET to be signed with its query, got %v", err)
+	}
+	resp.Body.Close()
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryTransportErrors_Unit(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	for name, tc := range map[string]struct {
+		err      error
+		attempts int32
+	}{
+		"connection reset":   {err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, attempts: 3},
+		"connection refused": {err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, attempts: 3},
+		"unexpected eof":     {err: io.ErrUnexpectedEOF, attempts: 3},
+		"certificate pin":    {err: ErrCertificatePin, attempts: 1},
+		"middleware":         {err: errors.New("middleware refused the request"), attempts: 1},
+	} {
+		var attempts int32
+		hc := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, tc.err
+		})}
+		client, err := NewClientWithOptions(uAppID, uAppKey, "localhost", 443, uRealm, true, false, WithHTTPClient(hc), WithRetryPolicy(policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Do(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if attempts != tc.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", name, tc.attempts, attempts)
+		}
+	}
+}
@@ -0,0 +1,615 @@
+package saidptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// route :
+//	non-exportable helper emulating the IdP api endpoints.
+func (s *Server) route(r *Request) (int, interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !strings.HasPrefix(r.Endpoint, "/api/v1/") {
+		return notFound("Endpoint was not found.")
+	}
+	segs := strings.Split(strings.TrimPrefix(r.Endpoint, "/api/v1/"), "/")
+	for i, seg := range segs {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segs[i] = unescaped
+		}
+	}
+	// Odd segments hold ids (users/{user}/groups/{group}) or actions (dfp/validate) and are matched as wildcards.
+	pattern := make([]string, len(segs))
+	for i, seg := range segs {
+		pattern[i] = seg
+		if i%2 == 1 {
+			pattern[i] = "*"
+		}
+	}
+	route := strings.Join(pattern, "/")
+	switch r.Method + " " + route {
+	case "POST auth":
+		return s.auth(r)
+	case "GET auth/*":
+		return s.pushStatus(segs[1])
+	case "POST users/*":
+		if segs[1] == "" {
+			return s.createUser(r)
+		}
+		return s.updateProfile(r, segs[1])
+	case "PUT users/*":
+		return s.updateProfile(r, segs[1])
+	case "GET users/*":
+		return s.profile(segs[1])
+	case "DELETE users/*":
+		return s.deleteUser(segs[1])
+	case "GET users/*/factors":
+		return s.factors(segs[1])
+	case "DELETE users/*/factors/*":
+		return s.deleteFactor(segs[1], segs[3])
+	case "GET users/*/throttle", "PUT users/*/throttle":
+		return s.throttle(r, segs[1])
+	case "POST users/*/resetpwd":
+		return s.resetPassword(r, segs[1])
+	case "POST users/*/changepwd":
+		return s.changePassword(r, segs[1])
+	case "POST users/*/groups":
+		return s.userGroups(r, segs[1])
+	case "POST users/*/groups/*":
+		return s.addToGroup(segs[1], segs[3])
+	case "POST groups/*/users":
+		return s.groupUsers(r, segs[1])
+	case "POST groups/*/users/*":
+		return s.addToGroup(segs[3], segs[1])
+	case "DELETE users/*/groups/*":
+		return s.removeFromGroup(segs[1], segs[3])
+	case "DELETE groups/*/users/*":
+		return s.removeFromGroup(segs[3], segs[1])
+	case "POST otp/*":
+		if segs[1] == "validate" {
+			return s.validateOTP(r)
+		}
+	case "GET dfp/*", "GET behavebio/*":
+		if segs[1] == "js" {
+			return http.StatusOK, map[string]interface{}{"src": s.URL + "/" + segs[0] + ".js", "status": "found", "message": ""}
+		}
+	case "POST dfp/*":
+		return s.dfp(r, segs[1])
+	case "POST behavebio", "PUT behavebio":
+		return s.behaveBio(r)
+	case "POST ipeval":
+		return s.ipEval(r)
+	case "POST adaptauth":
+		return s.adaptAuth(r)
+	case "POST numberprofile", "PUT numberprofile":
+		return s.numberProfile(r)
+	case "POST accesshistory":
+		return http.StatusOK, message("valid", "Access History request has been processed.")
+	case "POST oath":
+		return s.oath(r)
+	}
+	return notFound("Endpoint was not found.")
+}
+
+// auth :
+//	non-exportable helper emulating POST /api/v1/auth for every request type.
+func (s *Server) auth(r *Request) (int, interface{}) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Type     string `json:"type"`
+		Token    string `json:"token"`
+		FactorID string `json:"factor_id"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	u, ok := s.users[req.UserID]
+	if !ok {
+		return http.StatusOK, withUser(message("not_found", "User Id was not found."), req.UserID)
+	}
+	switch req.Type {
+	case "user_id":
+		return http.StatusOK, withUser(message("found", "User Id found"), u.ID)
+	case "password":
+		return http.StatusOK, s.check(u, u.Password, req.Token, "User Id or password is invalid.")
+	case "pin":
+		return http.StatusOK, s.check(u, u.PIN, req.Token, "PIN is invalid.")
+	case "oath":
+		return http.StatusOK, s.check(u, u.OATH, req.Token, "OATH OTP is invalid.")
+	case "kba":
+		return http.StatusOK, s.check(u, u.KBA[req.FactorID].Answer, req.Token, "KBA is invalid.")
+	case "call", "sms", "email", "push", "push_accept", "help_desk":
+		// Ad-hoc deliveries pass the destination in token instead of a factor id.
+		if _, ok := u.factor(req.FactorID); !ok && (req.Token == "" || req.Type == "push" || req.Type == "push_accept") {
+			return http.StatusOK, withUser(message("invalid", "Factor Id was not found."), u.ID)
+		}
+	default:
+		return badRequest("Request type is invalid.")
+	}
+	resp := withUser(message("valid", ""), u.ID)
+	switch req.Type {
+	case "push_accept":
+		s.sequence++
+		ref := fmt.Sprintf("00000000-0000-4000-8000-%012d", s.sequence)
+		statuses, ok := s.pushScripts[u.ID]
+		if !ok || len(statuses) == 0 {
+			statuses = []string{PushAccepted}
+		}
+		delete(s.pushScripts, u.ID)
+		s.pushes[ref] = statuses
+		resp["reference_id"] = ref
+	case "help_desk":
+	default:
+		s.sequence++
+		u.LastOTP = fmt.Sprintf("%06d", (123456+s.sequence*7919)%1000000)
+		resp["otp"] = u.LastOTP
+	}
+	return http.StatusOK, resp
+}
+
+// check :
+//	non-exportable helper validating a secret and counting failures toward the throttle count.
+func (s *Server) check(u *User, want string, got string, invalid string) map[string]interface{} {
+	if want != "" && want == got {
+		return withUser(message("valid", ""), u.ID)
+	}
+	u.ThrottleCount++
+	return withUser(message("invalid", invalid), u.ID)
+}
+
+// pushStatus :
+//	non-exportable helper returning the next scripted status of a push_accept request.
+func (s *Server) pushStatus(ref string) (int, interface{}) {
+	statuses, ok := s.pushes[ref]
+	if !ok {
+		return notFound("Reference Id was not found.")
+	}
+	if len(statuses) > 1 {
+		s.pushes[ref] = statuses[1:]
+	}
+	return http.StatusOK, message("valid", statuses[0])
+}
+
+// profileRequest : body accepted by the users endpoint.
+type profileRequest struct {
+	UserID        string            `json:"userId"`
+	Password      string            `json:"password"`
+	Properties    map[string]string `json:"properties"`
+	KnowledgeBase map[string]struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	} `json:"knowledgeBase"`
+}
+
+// createUser :
+//	non-exportable helper emulating POST /api/v1/users/.
+func (s *Server) createUser(r *Request) (int, interface{}) {
+	var req profileRequest
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	if req.UserID == "" {
+		return badRequest("UserId is required.")
+	}
+	if _, ok := s.users[req.UserID]; ok {
+		return badRequest("User already exists.")
+	}
+	s.users[req.UserID] = copyUser(User{ID: req.UserID})
+	return s.applyProfile(req, s.users[req.UserID])
+}
+
+// updateProfile :
+//	non-exportable helper emulating POST and PUT /api/v1/users/{user}.
+func (s *Server) updateProfile(r *Request, id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	var req profileRequest
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	return s.applyProfile(req, u)
+}
+
+// applyProfile :
+//	non-exportable helper storing the password, properties and knowledge base of a profile request.
+func (s *Server) applyProfile(req profileRequest, u *User) (int, interface{}) {
+	if req.Password != "" {
+		u.Password = req.Password
+	}
+	for k, v := range req.Properties {
+		u.Properties[k] = v
+	}
+	for k, v := range req.KnowledgeBase {
+		u.KBA[k] = KBA{Question: v.Question, Answer: v.Answer}
+	}
+	return http.StatusOK, message("success", "")
+}
+
+// profile :
+//	non-exportable helper emulating GET /api/v1/users/{user}. KBA answers are never returned.
+func (s *Server) profile(id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	props := map[string]interface{}{}
+	for k, v := range u.Properties {
+		props[k] = map[string]string{"value": v, "isWritable": "true", "displayName": k}
+	}
+	kb := map[string]interface{}{}
+	for k, v := range u.KBA {
+		kb[k] = map[string]string{"question": v.Question}
+	}
+	resp := message("found", "")
+	resp["userId"] = u.ID
+	resp["properties"] = props
+	resp["knowledgeBase"] = kb
+	resp["groups"] = u.Groups
+	return http.StatusOK, resp
+}
+
+// factors :
+//	non-exportable helper emulating GET /api/v1/users/{user}/factors.
+func (s *Server) factors(id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	resp := withUser(message("found", ""), u.ID)
+	resp["factors"] = append([]Factor{}, u.Factors...)
+	return http.StatusOK, resp
+}
+
+// deleteUser :
+//	non-exportable helper emulating DELETE /api/v1/users/{user}.
+func (s *Server) deleteUser(id string) (int, interface{}) {
+	if _, ok := s.users[id]; !ok {
+		return notFound("User Id was not found.")
+	}
+	delete(s.users, id)
+	delete(s.fingerprints, id)
+	return http.StatusOK, message("success", "")
+}
+
+// deleteFactor :
+//	non-exportable helper emulating DELETE /api/v1/users/{user}/factors/{factor}.
+func (s *Server) deleteFactor(id string, factorID string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	for i, f := range u.Factors {
+		if f.ID == factorID {
+			u.Factors = append(u.Factors[:i], u.Factors[i+1:]...)
+			return http.StatusOK, withUser(message("success", ""), u.ID)
+		}
+	}
+	return notFound("Factor Id was not found.")
+}
+
+// throttle :
+//	non-exportable helper emulating GET and PUT (reset) /api/v1/users/{user}/throttle.
+func (s *Server) throttle(r *Request, id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	if r.Method == http.MethodPut {
+		u.ThrottleCount = 0
+	}
+	resp := message("found", "")
+	resp["count"] = u.ThrottleCount
+	return http.StatusOK, resp
+}
+
+// resetPassword :
+//	non-exportable helper emulating POST /api/v1/users/{user}/resetpwd.
+func (s *Server) resetPassword(r *Request, id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil || req.Password == "" {
+		return badRequest("Password is required.")
+	}
+	u.Password = req.Password
+	return http.StatusOK, message("success", "Password was reset")
+}
+
+// changePassword :
+//	non-exportable helper emulating POST /api/v1/users/{user}/changepwd.
+func (s *Server) changePassword(r *Request, id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	var req struct {
+		Current string `json:"currentPassword"`
+		New     string `json:"newPassword"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil || req.New == "" {
+		return badRequest("Current and new passwords are required.")
+	}
+	if req.Current != u.Password {
+		u.ThrottleCount++
+		return http.StatusOK, message("invalid", "Current password is invalid.")
+	}
+	u.Password = req.New
+	return http.StatusOK, message("success", "Password was changed")
+}
+
+// addToGroup :
+//	non-exportable helper emulating POST /api/v1/users/{user}/groups/{group} and /api/v1/groups/{group}/users/{user}.
+func (s *Server) addToGroup(id string, group string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	u.addGroup(group)
+	return http.StatusOK, message("success", "")
+}
+
+// removeFromGroup :
+//	non-exportable helper emulating DELETE /api/v1/users/{user}/groups/{group} and /api/v1/groups/{group}/users/{user}.
+func (s *Server) removeFromGroup(id string, group string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	for i, g := range u.Groups {
+		if g == group {
+			u.Groups = append(u.Groups[:i], u.Groups[i+1:]...)
+			return http.StatusOK, message("success", "")
+		}
+	}
+	return notFound("Group was not found.")
+}
+
+// userGroups :
+//	non-exportable helper emulating POST /api/v1/users/{user}/groups.
+func (s *Server) userGroups(r *Request, id string) (int, interface{}) {
+	u, ok := s.users[id]
+	if !ok {
+		return notFound("User Id was not found.")
+	}
+	var req struct {
+		GroupNames []string `json:"groupNames"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	for _, g := range req.GroupNames {
+		u.addGroup(g)
+	}
+	return http.StatusOK, message("success", "")
+}
+
+// groupUsers :
+//	non-exportable helper emulating POST /api/v1/groups/{group}/users. Unknown users are reported as failures.
+func (s *Server) groupUsers(r *Request, group string) (int, interface{}) {
+	var req struct {
+		UserIDs []string `json:"userIds"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	failures := map[string][]string{}
+	for _, id := range req.UserIDs {
+		u, ok := s.users[id]
+		if !ok {
+			failures[id] = []string{"User Id was not found."}
+			continue
+		}
+		u.addGroup(group)
+	}
+	resp := message("success", "")
+	if len(failures) > 0 {
+		resp["failures"] = failures
+	}
+	return http.StatusOK, resp
+}
+
+// validateOTP :
+//	non-exportable helper emulating POST /api/v1/otp/validate against the last delivered OTP or the user OATH OTP.
+func (s *Server) validateOTP(r *Request) (int, interface{}) {
+	var req struct {
+		UserID string `json:"user_id"`
+		OTP    string `json:"otp"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	u, ok := s.users[req.UserID]
+	if !ok {
+		return http.StatusOK, withUser(message("not_found", "User Id was not found."), req.UserID)
+	}
+	if req.OTP != "" && req.OTP == u.LastOTP {
+		return http.StatusOK, withUser(message("valid", ""), u.ID)
+	}
+	return http.StatusOK, s.check(u, u.OATH, req.OTP, "OTP is invalid.")
+}
+
+// dfp :
+//	non-exportable helper emulating the dfp validate, score, confirm and save endpoints. A fingerprint matches
+//	once it has been saved or confirmed for the user.
+func (s *Server) dfp(r *Request, action string) (int, interface{}) {
+	var req struct {
+		UserID        string `json:"user_id"`
+		FingerprintID string `json:"fingerprint_id"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	if _, ok := s.users[req.UserID]; !ok {
+		return http.StatusOK, withUser(message("not_found", "User Id was not found."), req.UserID)
+	}
+	known := s.fingerprints[req.UserID]
+	if known == nil {
+		known = map[string]bool{}
+		s.fingerprints[req.UserID] = known
+	}
+	switch action {
+	case "validate", "score":
+		resp := withUser(message("not_found", ""), req.UserID)
+		resp["score"], resp["match_score"], resp["update_score"] = "0.00", "0.00", "0.00"
+		if known[req.FingerprintID] {
+			resp["status"] = "found"
+			resp["fingerprint_id"] = req.FingerprintID
+			resp["score"], resp["match_score"], resp["update_score"] = "100.00", "100.00", "100.00"
+		}
+		return http.StatusOK, resp
+	case "confirm", "save":
+		if req.FingerprintID == "" {
+			s.sequence++
+			req.FingerprintID = fmt.Sprintf("fp-%d", s.sequence)
+		}
+		known[req.FingerprintID] = true
+		resp := withUser(message("verified", "Fingerprint has been confirmed."), req.UserID)
+		resp["fingerprint_id"] = req.FingerprintID
+		return http.StatusOK, resp
+	}
+	return notFound("Endpoint was not found.")
+}
+
+// behaveBio :
+//	non-exportable helper emulating POST (score) and PUT (reset) /api/v1/behavebio.
+func (s *Server) behaveBio(r *Request) (int, interface{}) {
+	if r.Method == http.MethodPut {
+		return http.StatusOK, message("success", "Reset sent to data store")
+	}
+	resp := message("found", "")
+	resp["BehaviorBioResults"] = map[string]interface{}{"TotalScore": 1, "TotalConfidence": 1, "Device": "Desktop"}
+	return http.StatusOK, resp
+}
+
+// ipEval :
+//	non-exportable helper emulating POST /api/v1/ipeval with a low risk evaluation.
+func (s *Server) ipEval(r *Request) (int, interface{}) {
+	var req struct {
+		IPAddress string `json:"ip_address"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	resp := message("verified", "")
+	resp["ip_evaluation"] = map[string]interface{}{
+		"method": "aggregation", "ip": req.IPAddress, "risk_factor": 0, "risk_color": "green", "risk_desc": "Very Low Risk",
+	}
+	return http.StatusOK, resp
+}
+
+// adaptAuth :
+//	non-exportable helper emulating POST /api/v1/adaptauth with a continue action for known users.
+func (s *Server) adaptAuth(r *Request) (int, interface{}) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	if _, ok := s.users[req.UserID]; !ok {
+		return http.StatusOK, message("not_found", "User Id was not found.")
+	}
+	resp := message("found", "")
+	resp["realm_workflow"] = "none"
+	resp["suggested_action"] = "continue"
+	return http.StatusOK, resp
+}
+
+// numberProfile :
+//	non-exportable helper emulating POST (evaluate) and PUT (update carrier) /api/v1/numberprofile.
+func (s *Server) numberProfile(r *Request) (int, interface{}) {
+	var req struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	if r.Method == http.MethodPut {
+		return http.StatusOK, message("success", "")
+	}
+	resp := message("found", "")
+	resp["numberProfileResult"] = map[string]interface{}{
+		"internationalFormat": req.PhoneNumber,
+		"validNumber":         true,
+		"currentCarrier":      map[string]string{"carrier": "Test Carrier", "networkType": "mobile"},
+	}
+	return http.StatusOK, resp
+}
+
+// oath :
+//	non-exportable helper emulating POST /api/v1/oath, returning fixed oath settings for valid credentials.
+func (s *Server) oath(r *Request) (int, interface{}) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Password string `json:"password"`
+		Token    string `json:"token"`
+	}
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return badRequest(err.Error())
+	}
+	u, ok := s.users[req.UserID]
+	if !ok || u.Password == "" || u.Password != req.Password || u.OATH == "" || u.OATH != req.Token {
+		return badRequest("User Id, password or token is invalid.")
+	}
+	return http.StatusOK, map[string]string{
+		"server_time": time.Now().UTC().Format(time.RFC1123), "key": "3132333435363738393031323334353637383930",
+		"interval": "30", "length": "6", "offset": "0", "pin_control": "0", "failed_wipe": "0", "screen_timeout": "0",
+	}
+}
+
+// withUser :
+//	non-exportable helper adding the user_id field to a response.
+func withUser(resp map[string]interface{}, id string) map[string]interface{} {
+	resp["user_id"] = id
+	return resp
+}
+
+// notFound :
+//	non-exportable helper building a 404 response.
+func notFound(msg string) (int, interface{}) {
+	return http.StatusNotFound, message("not_found", msg)
+}
+
+// badRequest :
+//	non-exportable helper building a 400 response.
+func badRequest(msg string) (int, interface{}) {
+	return http.StatusBadRequest, message("invalid", msg)
+}
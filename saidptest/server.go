@@ -0,0 +1,257 @@
+// Package saidptest provides an in-process fake SecureAuth IdP API for tests. The server verifies the HMAC
+// Authorization header of every call, signs its responses with X-SA-Signature and keeps users in memory.
+package saidptest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Credentials used by servers created with NewServer.
+const (
+	DefaultAppID  = "af1b351845ec47968b27debd9cd4ce53"
+	DefaultAppKey = "101db0347fdf71dab63cd965b8782ff6ba0f8f1c91e8cf52f970d1267e0fb453"
+	DefaultRealm  = "secureauth1"
+)
+
+// Push accept statuses returned when polling /api/v1/auth/{ref_id}.
+const (
+	PushPending  = "PENDING"
+	PushAccepted = "ACCEPTED"
+	PushDenied   = "DENIED"
+	PushFailed   = "FAILED"
+	PushExpired  = "EXPIRED"
+)
+
+// Request :
+//	A call received by the server, as seen by HandlerFunc overrides and returned by Requests.
+// Fields:
+//	Method: the http verb.
+//	Endpoint: the api endpoint after the realm, e.g. /api/v1/users/jsmith/factors.
+//	Header: the request headers.
+//	Body: the raw request body.
+type Request struct {
+	Method   string
+	Endpoint string
+	Header   http.Header
+	Body     []byte
+}
+
+// HandlerFunc :
+//	Scripted endpoint used instead of the built in emulation. The returned value is marshaled to JSON and signed.
+type HandlerFunc func(r *Request) (int, interface{})
+
+// Server :
+//	Fake SecureAuth IdP realm backed by an httptest.Server.
+// Fields:
+//	AppID, AppKey, Realm: the credentials and realm the server accepts.
+//	MaxSkew: maximum difference allowed between the request Date and the server clock, 5 minutes by default.
+type Server struct {
+	*httptest.Server
+	AppID   string
+	AppKey  string
+	Realm   string
+	MaxSkew time.Duration
+
+	mu           sync.Mutex
+	users        map[string]*User
+	pushScripts  map[string][]string
+	pushes       map[string][]string
+	fingerprints map[string]map[string]bool
+	overrides    map[string]HandlerFunc
+	requests     []Request
+	sequence     int
+}
+
+// NewServer :
+//	Function starting a fake IdP realm using DefaultAppID, DefaultAppKey and DefaultRealm. Close must be called
+//	when the test is done.
+// Returns:
+//	Server: the running server.
+func NewServer() *Server {
+	return NewServerWithCredentials(DefaultAppID, DefaultAppKey, DefaultRealm)
+}
+
+// NewServerWithCredentials :
+//	Function starting a fake IdP realm accepting the given credentials. Close must be called when the test is done.
+// Parameters:
+//	[Required] appID: the application id clients must sign with.
+//	[Required] appKey: the hex encoded application key.
+//	[Required] realm: the realm name, e.g. secureauth1.
+// Returns:
+//	Server: the running server.
+func NewServerWithCredentials(appID string, appKey string, realm string) *Server {
+	s := &Server{
+		AppID:        appID,
+		AppKey:       appKey,
+		Realm:        realm,
+		MaxSkew:      5 * time.Minute,
+		users:        map[string]*User{},
+		pushScripts:  map[string][]string{},
+		pushes:       map[string][]string{},
+		fingerprints: map[string]map[string]bool{},
+		overrides:    map[string]HandlerFunc{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client :
+//	Function building a client that talks to the server with its credentials.
+// Parameters:
+//	opts: additional client options.
+// Returns:
+//	sa.Client: client pointed at the server.
+//	Error: If an error is encountered, client will be nil and the error must be handled.
+func (s *Server) Client(opts ...sa.ClientOption) (*sa.Client, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, err
+	}
+	return sa.NewClientWithOptions(s.AppID, s.AppKey, u.Hostname(), port, s.Realm, false, false, opts...)
+}
+
+// Handle :
+//	Function scripting the outcome of an endpoint, replacing the built in emulation. Requests are still
+//	authenticated and responses are still signed.
+// Parameters:
+//	[Required] method: the http verb to match.
+//	[Required] endpoint: the api endpoint after the realm to match, e.g. /api/v1/ipeval.
+//	[Required] h: the handler producing the status code and body.
+func (s *Server) Handle(method string, endpoint string, h HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[method+" "+endpoint] = h
+}
+
+// Requests :
+//	Function returning every authenticated call received so far, in order.
+// Returns:
+//	[]Request: the recorded calls.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// serveHTTP :
+//	non-exportable helper authenticating, recording and dispatching every call.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := "/" + s.Realm
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, prefix+"/") {
+		s.write(w, http.StatusNotFound, message("not_found", "Realm was not found."))
+		return
+	}
+	if !s.authorized(r) {
+		s.write(w, http.StatusUnauthorized, message("invalid", "AppId or signature is invalid."))
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.write(w, http.StatusBadRequest, message("invalid", err.Error()))
+		return
+	}
+	req := &Request{Method: r.Method, Endpoint: strings.TrimPrefix(path, prefix), Header: r.Header.Clone(), Body: body}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	h, ok := s.overrides[req.Method+" "+req.Endpoint]
+	s.mu.Unlock()
+	if !ok {
+		h = s.route
+	}
+	code, resp := h(req)
+	s.write(w, code, resp)
+}
+
+// authorized :
+//	non-exportable helper verifying the Authorization header and the request date against the server credentials.
+func (s *Server) authorized(r *http.Request) bool {
+	_, err := sa.VerifyRequest(r, func(appID string) (string, error) {
+		if appID != s.AppID {
+			return "", errors.New("unknown app id")
+		}
+		return s.AppKey, nil
+	}, s.MaxSkew)
+	return err == nil
+}
+
+// write :
+//	non-exportable helper writing a JSON response signed with X-SA-Date and X-SA-Signature.
+func (s *Server) write(w http.ResponseWriter, code int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		code, body = http.StatusInternalServerError, []byte(`{"status":"error","message":"unable to marshal response"}`)
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	var payload bytes.Buffer
+	payload.WriteString(date + "\n" + s.AppID + "\n")
+	payload.Write(body)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-SA-Date", date)
+	w.Header().Set("X-SA-Signature", s.hmac(payload.Bytes()))
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// hmac :
+//	non-exportable helper computing the base64 HMAC-SHA256 of data with the hex encoded application key.
+func (s *Server) hmac(data []byte) string {
+	key, _ := hex.DecodeString(s.AppKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// message :
+//	non-exportable helper building the status/message body shared by every endpoint.
+func message(status string, msg string) map[string]interface{} {
+	return map[string]interface{}{"status": status, "message": msg}
+}
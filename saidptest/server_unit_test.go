@@ -0,0 +1,186 @@
+package saidptest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"github.com/secureauthcorp/saidp-sdk-go/services/auth"
+	"github.com/secureauthcorp/saidp-sdk-go/services/factors"
+	"github.com/secureauthcorp/saidp-sdk-go/services/groups"
+	"github.com/secureauthcorp/saidp-sdk-go/services/otp"
+	"github.com/secureauthcorp/saidp-sdk-go/services/throttle"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+const uUser = "jsmith"
+
+func newTestServer(t *testing.T) (*Server, *sa.Client) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(User{
+		ID:       uUser,
+		Password: "p@ssw0rd",
+		Factors: []Factor{
+			{Type: "phone", ID: "Phone1", Value: "xxx-xxx-1234", Capabilities: []string{"sms", "call"}},
+			{Type: "push", ID: "device1", Value: "iPhone", Capabilities: []string{"push", "push_accept"}},
+		},
+	})
+	client, err := server.Client(sa.WithSignatureVerification(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func TestServer_Unit(t *testing.T) {
+	server, client := newTestServer(t)
+	factorsResponse, err := new(factors.Request).Get(client, uUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factorsResponse.Status != "found" || len(factorsResponse.Factors) != 2 {
+		t.Errorf("unexpected factors response: %s", factorsResponse.RawJSON)
+	}
+	if valid, err := factorsResponse.IsSignatureValid(client); !valid || err != nil {
+		t.Errorf("expected a signed response, got %v %v", valid, err)
+	}
+	if _, err := new(factors.Request).Get(client, "nobody"); !errors.Is(err, sa.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown user, got %v", err)
+	}
+
+	sms, err := new(auth.Request).SendSMSOtp(client, uUser, "Phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := server.User(uUser); sms.OTP == "" || u.LastOTP != sms.OTP {
+		t.Errorf("expected the delivered otp to be recorded, got %q", sms.OTP)
+	}
+	otpResponse, err := new(otp.Request).ValidateOTP(client, uUser, "", sms.OTP)
+	if err != nil || otpResponse.Status != "valid" {
+		t.Errorf("expected the delivered otp to validate, got %v %v", otpResponse, err)
+	}
+
+	if resp, err := new(auth.Request).ValidatePassword(client, uUser, "wrong"); err != nil || resp.Status != "invalid" {
+		t.Errorf("expected an invalid password, got %v %v", resp, err)
+	}
+	throttleResponse, err := new(throttle.Request).Get(client, uUser)
+	if err != nil || throttleResponse.Count != 1 {
+		t.Errorf("expected the failed attempt to be counted, got %v %v", throttleResponse, err)
+	}
+	server.SetThrottleCount(uUser, 4)
+	if throttleResponse, err = new(throttle.Request).Get(client, uUser); err != nil || throttleResponse.Count != 4 {
+		t.Errorf("expected the scripted throttle count, got %v %v", throttleResponse, err)
+	}
+
+	if _, err := new(groups.Request).AddUserToGroup(client, uUser, "Admins"); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := server.User(uUser); len(u.Groups) != 1 || u.Groups[0] != "Admins" {
+		t.Errorf("unexpected groups: %v", u.Groups)
+	}
+	if _, err := new(groups.Request).RemoveUserFromGroup(client, uUser, "Admins"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := new(factors.Request).Delete(client, uUser, "Phone1"); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := server.User(uUser); len(u.Groups) != 0 || len(u.Factors) != 1 {
+		t.Errorf("expected the group and factor to be removed, got %v %v", u.Groups, u.Factors)
+	}
+}
+
+func TestServerPushAccept_Unit(t *testing.T) {
+	server, client := newTestServer(t)
+	server.ScriptPush(uUser, PushPending, PushDenied)
+	push, err := new(auth.Request).SendPushAccept(client, uUser, "device1", "Acme", "Portal", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{PushPending, PushDenied, PushDenied} {
+		status, err := new(auth.Request).Get(client, push.RefID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Message != want {
+			t.Errorf("expected %s, got %s", want, status.Message)
+		}
+	}
+}
+
+func TestServerRejectsBadSignature_Unit(t *testing.T) {
+	server, good := newTestServer(t)
+	client := *good
+	client.AppKey = "00112233445566778899aabbccddeeff"
+	if _, err := new(factors.Request).Get(&client, uUser); !errors.Is(err, sa.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+
+	server.Handle(http.MethodGet, "/api/v1/users/"+uUser+"/factors", func(r *Request) (int, interface{}) {
+		return http.StatusOK, map[string]string{"status": "found", "message": "scripted"}
+	})
+	resp, err := new(factors.Request).Get(good, uUser)
+	if err != nil || resp.Message != "scripted" {
+		t.Errorf("expected the scripted response, got %v %v", resp, err)
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("expected only authenticated calls to be recorded, got %d", len(server.Requests()))
+	}
+}
+
+func TestServerServices_Unit(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestServer(t)
+	sms, err := auth.NewService(client).SendSMS(ctx, uUser, "Phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := otp.NewService(client).ValidateOTP(ctx, uUser, "", sms.OTP); err != nil || resp.Status != "valid" {
+		t.Errorf("expected the delivered otp to validate, got %v %v", resp, err)
+	}
+	if _, err := groups.NewService(client).AddUserToGroups(ctx, uUser, []string{"Admins", "Users"}); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := server.User(uUser); len(u.Groups) != 2 {
+		t.Errorf("unexpected groups: %v", u.Groups)
+	}
+	server.SetThrottleCount(uUser, 2)
+	throttles := throttle.NewService(client)
+	if _, err := throttles.Reset(ctx, uUser); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := throttles.Get(ctx, uUser); err != nil || resp.Count != 0 {
+		t.Errorf("expected the throttle count to be reset, got %v %v", resp, err)
+	}
+	if resp, err := factors.NewService(client).Get(ctx, uUser); err != nil || len(resp.Factors) != 2 {
+		t.Errorf("unexpected factors response %v %v", resp, err)
+	}
+}
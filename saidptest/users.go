@@ -0,0 +1,167 @@
+package saidptest
+
+import (
+	"sort"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// User :
+//	In-memory user profile served by the fake realm.
+// Fields:
+//	[Required] ID: the user id.
+//	Password: the password accepted by auth (type password), resetpwd and changepwd.
+//	PIN: the pin accepted by auth (type pin).
+//	OATH: the one time passcode accepted by auth (type oath) and otp/validate, in addition to delivered OTPs.
+//	Properties: profile properties by name, e.g. firstName, email1, phone1, pinHash.
+//	KBA: knowledge based questions by id (kbq1..kbq6, helpDeskKb).
+//	Factors: the factors returned by /api/v1/users/{user}/factors.
+//	Groups: the groups the user belongs to.
+//	ThrottleCount: the number of failed attempts; incremented by failed validations, reset by PUT throttle.
+//	LastOTP: the last OTP delivered by a call, sms, email or push auth request.
+type User struct {
+	ID            string
+	Password      string
+	PIN           string
+	OATH          string
+	Properties    map[string]string
+	KBA           map[string]KBA
+	Factors       []Factor
+	Groups        []string
+	ThrottleCount int
+	LastOTP       string
+}
+
+// KBA :
+//	Knowledge based question and its answer.
+type KBA struct {
+	Question string
+	Answer   string
+}
+
+// Factor :
+//	Factor returned for a user, mirroring the factors endpoint.
+type Factor struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"id,omitempty"`
+	Value        string   `json:"value"`
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// AddUser :
+//	Function adding or replacing a user in the realm.
+// Parameters:
+//	[Required] u: the user profile.
+func (s *Server) AddUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[u.ID] = copyUser(u)
+}
+
+// User :
+//	Function returning a snapshot of a user, e.g. to assert on LastOTP, ThrottleCount or Groups.
+// Parameters:
+//	[Required] id: the user id.
+// Returns:
+//	User: copy of the user profile.
+//	bool: false when the user does not exist.
+func (s *Server) User(id string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, false
+	}
+	return *copyUser(*u), true
+}
+
+// SetThrottleCount :
+//	Function scripting the throttle count returned for a user.
+// Parameters:
+//	[Required] id: the user id.
+//	[Required] count: the failed attempt count.
+func (s *Server) SetThrottleCount(id string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[id]; ok {
+		u.ThrottleCount = count
+	}
+}
+
+// ScriptPush :
+//	Function scripting the statuses returned when polling the next push_accept request of a user. Each poll
+//	returns the next status and the last one repeats, e.g. ScriptPush("jsmith", PushPending, PushPending, PushAccepted).
+//	Unscripted push_accept requests are accepted on the first poll.
+// Parameters:
+//	[Required] id: the user id.
+//	[Required] statuses: the statuses to return, in order.
+func (s *Server) ScriptPush(id string, statuses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushScripts[id] = append([]string(nil), statuses...)
+}
+
+// copyUser :
+//	non-exportable helper deep copying a user so callers never share maps or slices with the server.
+func copyUser(u User) *User {
+	c := u
+	c.Properties = make(map[string]string, len(u.Properties))
+	for k, v := range u.Properties {
+		c.Properties[k] = v
+	}
+	c.KBA = make(map[string]KBA, len(u.KBA))
+	for k, v := range u.KBA {
+		c.KBA[k] = v
+	}
+	c.Factors = append([]Factor(nil), u.Factors...)
+	c.Groups = append([]string(nil), u.Groups...)
+	return &c
+}
+
+// addGroup :
+//	non-exportable helper adding a group to a user once.
+func (u *User) addGroup(group string) {
+	for _, g := range u.Groups {
+		if g == group {
+			return
+		}
+	}
+	u.Groups = append(u.Groups, group)
+	sort.Strings(u.Groups)
+}
+
+// factor :
+//	non-exportable helper looking up a factor by id.
+func (u *User) factor(id string) (Factor, bool) {
+	for _, f := range u.Factors {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Factor{}, false
+}
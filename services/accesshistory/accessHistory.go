@@ -1,13 +1,7 @@
 package accesshistory
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -70,31 +64,20 @@ type Request struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	accessResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, accessResponse); err != nil {
-		return nil, err
-	}
-	accessResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	accessResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return accessResponse, nil
+	return r.PostContext(context.Background(), c)
+}
+
+// PostContext :
+//	Executes a post to the access history endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // SetAccessHistory :
@@ -107,13 +90,14 @@ func (r *Request) Post(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SetAccessHistory(c *sa.Client, userID string, ipAddress string) (*Response, error) {
-	r.UserID = userID
-	r.IPAddress = ipAddress
-	aHistoryResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return aHistoryResponse, nil
+	return NewService(c).SetAccessHistory(context.Background(), userID, ipAddress)
+}
+
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
 }
 
 //IsSignatureValid :
@@ -123,24 +107,7 @@ func (r *Request) SetAccessHistory(c *sa.Client, userID string, ipAddress string
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -44,7 +44,7 @@ import (
 
 const (
 	uAppID  = "12345"
-	uAppKey = "12345"
+	uAppKey = "123456"
 	uHost   = "idp.host.com"
 	uRealm  = "secureauth1"
 	uPort   = 443
@@ -60,6 +60,7 @@ func TestAdaptAuth_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	n := time.Now()
 	headers := map[string]string{
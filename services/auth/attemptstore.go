@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// AttemptStore :
+//	Server side record of the flows in progress, keyed by FlowState.ID. It makes every token built by
+//	Flow.Encode single use and counts failed OTP attempts and challenges out of the user's reach, so replaying an
+//	older token can neither reset MaxAttempts and MaxChallenges nor be used to guess a delivered OTP. Stores
+//	shared by several instances (e.g. backed by Redis or a database) must make each function atomic.
+type AttemptStore interface {
+	// Issue makes nonce the only token of the flow that Decode accepts, replacing the previous one, until expiresAt.
+	Issue(ctx context.Context, flowID string, nonce string, expiresAt time.Time) error
+	// Redeem reports whether nonce is bound to the current token of the flow, and invalidates that token if so.
+	Redeem(ctx context.Context, flowID string, nonce string) (bool, error)
+	// AddAttempt counts an OTP attempt of the flow and returns the number of attempts counted so far.
+	AddAttempt(ctx context.Context, flowID string, expiresAt time.Time) (int, error)
+	// AddChallenge counts a challenge of the flow and returns the number of challenges counted so far.
+	AddChallenge(ctx context.Context, flowID string, expiresAt time.Time) (int, error)
+}
+
+// MemoryAttemptStore :
+//	AttemptStore keeping the flows in memory, the default of NewFlow. It only protects flows served by a single
+//	instance; use a shared store when requests of one flow can reach several instances.
+// Fields:
+//	Now: clock used to expire flows. Defaults to time.Now.
+type MemoryAttemptStore struct {
+	Now   func() time.Time
+	mu    sync.Mutex
+	flows map[string]*memoryFlow
+	swept time.Time
+}
+
+// memoryFlow :
+//	non-exportable record of one flow in a MemoryAttemptStore.
+type memoryFlow struct {
+	nonce      string
+	attempts   int
+	challenges int
+	expiresAt  time.Time
+}
+
+// NewMemoryAttemptStore :
+//	Helper function to create an empty MemoryAttemptStore.
+// Returns:
+//	MemoryAttemptStore: the store.
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{Now: time.Now, flows: make(map[string]*memoryFlow)}
+}
+
+// Issue :
+//	Implements AttemptStore.
+func (s *MemoryAttemptStore) Issue(ctx context.Context, flowID string, nonce string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flow(flowID, expiresAt).nonce = nonce
+	return nil
+}
+
+// Redeem :
+//	Implements AttemptStore.
+func (s *MemoryAttemptStore) Redeem(ctx context.Context, flowID string, nonce string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flow, ok := s.flows[flowID]
+	if !ok || flow.nonce == "" || s.now().After(flow.expiresAt) {
+		return false, nil
+	}
+	if flow.nonce != nonce {
+		return false, nil
+	}
+	flow.nonce = ""
+	return true, nil
+}
+
+// AddAttempt :
+//	Implements AttemptStore.
+func (s *MemoryAttemptStore) AddAttempt(ctx context.Context, flowID string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flow := s.flow(flowID, expiresAt)
+	flow.attempts++
+	return flow.attempts, nil
+}
+
+// AddChallenge :
+//	Implements AttemptStore.
+func (s *MemoryAttemptStore) AddChallenge(ctx context.Context, flowID string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flow := s.flow(flowID, expiresAt)
+	flow.challenges++
+	return flow.challenges, nil
+}
+
+// flow :
+//	non-exportable helper returning the record of a flow, creating it on first use. Expired records are dropped
+//	at most once a minute. The caller must hold the lock.
+func (s *MemoryAttemptStore) flow(flowID string, expiresAt time.Time) *memoryFlow {
+	now := s.now()
+	if now.Sub(s.swept) > time.Minute {
+		for id, flow := range s.flows {
+			if now.After(flow.expiresAt) {
+				delete(s.flows, id)
+			}
+		}
+		s.swept = now
+	}
+	if s.flows == nil {
+		s.flows = make(map[string]*memoryFlow)
+	}
+	flow, ok := s.flows[flowID]
+	if !ok {
+		flow = &memoryFlow{}
+		s.flows[flowID] = flow
+	}
+	if expiresAt.After(flow.expiresAt) {
+		flow.expiresAt = expiresAt
+	}
+	return flow
+}
+
+// now :
+//	non-exportable helper returning the current time from Now, or time.Now when it is not set.
+func (s *MemoryAttemptStore) now() time.Time {
+	if s.Now == nil {
+		return time.Now()
+	}
+	return s.Now()
+}
@@ -2,13 +2,8 @@ package auth
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"errors"
-	"io/ioutil"
+	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -60,10 +55,11 @@ type Response struct {
 }
 
 // Request :
-//	Request struct to build the required post parameters.
+//	Request struct to build the required post parameters. The helper functions on Request wrap Service and
+//	leave the receiver untouched; use a Service directly to share one client across goroutines.
 // Fields:
 //	[Required] UserId: the username that you want to submit access history for.
-//	[Required] ReqType: type of auth request, valid entries: user_id, password, kba, oath,
+//	[Required] ReqType: type of auth request, valid entries (see the Type constants): user_id, password, kba, oath,
 // 		   pin, call, sms, email, push, push_accept, help_desk
 //	Token: used to pass data for validation
 //	[Required] FactorID: identifier to which attribute in the users profile the request type
@@ -102,31 +98,24 @@ type PushAcceptDetails struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	authResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, authResponse); err != nil {
-		return nil, err
+	return r.PostContext(context.Background(), c)
+}
+
+// PostContext :
+//	Executes a post to the auth endpoint, bound to ctx. An unknown ReqType fails with ErrUnknownAuthType before
+//	any network call.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client) (*Response, error) {
+	if !validators.ValidateAuthType(r.ReqType) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAuthType, r.ReqType)
 	}
-	authResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	authResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return authResponse, nil
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // Get :
@@ -138,28 +127,21 @@ func (r *Request) Post(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Get(c *sa.Client, refID string) (*Response, error) {
+	return r.GetContext(context.Background(), c, refID)
+}
+
+// GetContext :
+//	Executes a get request for checking push to accept status, bound to ctx.
+// Parameters:
+//	[Required] r: empty struct used to make Get easy.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) GetContext(ctx context.Context, c *sa.Client, refID string) (*Response, error) {
 	endpoint := buildEndpointPath(refID)
-	httpRequest, err := c.BuildGetRequest(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	authResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, authResponse); err != nil {
-		return nil, err
-	}
-	authResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	authResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return authResponse, nil
+	return sa.Execute[Response](ctx, c, http.MethodGet, endpoint, nil)
 }
 
 // ValidateUser :
@@ -171,13 +153,7 @@ func (r *Request) Get(c *sa.Client, refID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidateUser(c *sa.Client, userID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "user_id"
-	validateResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return validateResponse, nil
+	return NewService(c).ValidateUser(context.Background(), userID)
 }
 
 // ValidatePassword :
@@ -190,14 +166,7 @@ func (r *Request) ValidateUser(c *sa.Client, userID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidatePassword(c *sa.Client, userID string, password string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "password"
-	r.Token = password
-	passwordResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return passwordResponse, nil
+	return NewService(c).ValidatePassword(context.Background(), userID, password)
 }
 
 // ValidateKba :
@@ -211,15 +180,7 @@ func (r *Request) ValidatePassword(c *sa.Client, userID string, password string)
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidateKba(c *sa.Client, userID string, answer string, kbqID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "kba"
-	r.Token = answer
-	r.FactorID = kbqID
-	kbaResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return kbaResponse, nil
+	return NewService(c).ValidateKba(context.Background(), userID, answer, kbqID)
 }
 
 // ValidateOath :
@@ -233,15 +194,7 @@ func (r *Request) ValidateKba(c *sa.Client, userID string, answer string, kbqID
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidateOath(c *sa.Client, userID string, oathOTP string, deviceID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "oath"
-	r.Token = oathOTP
-	r.FactorID = deviceID
-	oathResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return oathResponse, nil
+	return NewService(c).ValidateOath(context.Background(), userID, oathOTP, deviceID)
 }
 
 // ValidatePin :
@@ -254,14 +207,7 @@ func (r *Request) ValidateOath(c *sa.Client, userID string, oathOTP string, devi
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidatePin(c *sa.Client, userID string, pin string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "pin"
-	r.Token = pin
-	pinResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return pinResponse, nil
+	return NewService(c).ValidatePin(context.Background(), userID, pin)
 }
 
 // SendOtpAdHoc :
@@ -276,21 +222,11 @@ func (r *Request) ValidatePin(c *sa.Client, userID string, pin string) (*Respons
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendOtpAdHoc(c *sa.Client, userID string, token string, reqType string, eval bool) (*Response, error) {
-	if !validators.ValidateRequestType(reqType) {
-		return nil, errors.New("Not a valid type, valid types: call, sms, or email")
-	}
-	if (eval) && (reqType == "email") {
-		return nil, errors.New("Number evaluation can only be used with call or sms reqTypes")
+	var opts []Option
+	if eval {
+		opts = append(opts, WithNumberEval())
 	}
-	r.UserID = userID
-	r.ReqType = reqType
-	r.Token = token
-	r.EvaluateNum = eval
-	adHocResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return adHocResponse, nil
+	return NewService(c).SendAdHoc(context.Background(), userID, token, AuthType(reqType), opts...)
 }
 
 // SendCallOtp :
@@ -304,14 +240,7 @@ func (r *Request) SendOtpAdHoc(c *sa.Client, userID string, token string, reqTyp
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendCallOtp(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "call"
-	r.FactorID = factorID
-	callResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return callResponse, nil
+	return NewService(c).SendCall(context.Background(), userID, factorID)
 }
 
 // SendCallOtpWithEval :
@@ -325,15 +254,7 @@ func (r *Request) SendCallOtp(c *sa.Client, userID string, factorID string) (*Re
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendCallOtpWithEval(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "call"
-	r.FactorID = factorID
-	r.EvaluateNum = true
-	callResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return callResponse, nil
+	return NewService(c).SendCall(context.Background(), userID, factorID, WithNumberEval())
 }
 
 // SendSMSOtp :
@@ -347,14 +268,7 @@ func (r *Request) SendCallOtpWithEval(c *sa.Client, userID string, factorID stri
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendSMSOtp(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "sms"
-	r.FactorID = factorID
-	smsResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return smsResponse, nil
+	return NewService(c).SendSMS(context.Background(), userID, factorID)
 }
 
 // SendSMSOtpWithEval :
@@ -368,15 +282,7 @@ func (r *Request) SendSMSOtp(c *sa.Client, userID string, factorID string) (*Res
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendSMSOtpWithEval(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "sms"
-	r.FactorID = factorID
-	r.EvaluateNum = true
-	smsResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return smsResponse, nil
+	return NewService(c).SendSMS(context.Background(), userID, factorID, WithNumberEval())
 }
 
 // SendEmailOtp :
@@ -390,14 +296,7 @@ func (r *Request) SendSMSOtpWithEval(c *sa.Client, userID string, factorID strin
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendEmailOtp(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "email"
-	r.FactorID = factorID
-	emailResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return emailResponse, nil
+	return NewService(c).SendEmail(context.Background(), userID, factorID)
 }
 
 // SendPushNotify :
@@ -410,14 +309,7 @@ func (r *Request) SendEmailOtp(c *sa.Client, userID string, factorID string) (*R
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendPushNotify(c *sa.Client, userID string, deviceID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "push"
-	r.FactorID = deviceID
-	pushResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return pushResponse, nil
+	return NewService(c).SendPush(context.Background(), userID, deviceID)
 }
 
 // SendPushAccept :
@@ -433,19 +325,7 @@ func (r *Request) SendPushNotify(c *sa.Client, userID string, deviceID string) (
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendPushAccept(c *sa.Client, userID string, deviceID string, companyName string, appDesc string, userIP string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "push_accept"
-	r.FactorID = deviceID
-	pushDetails := new(PushAcceptDetails)
-	pushDetails.CompanyName = companyName
-	pushDetails.AppDesc = appDesc
-	pushDetails.EnduserIP = userIP
-	r.PushDetails = pushDetails
-	pushResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return pushResponse, nil
+	return NewService(c).SendPushAccept(context.Background(), userID, deviceID, WithPushDetails(companyName, appDesc, userIP))
 }
 
 // CheckPushAcceptStatus :
@@ -457,26 +337,31 @@ func (r *Request) SendPushAccept(c *sa.Client, userID string, deviceID string, c
 //	[Required] interval: the frequency (in seconds) in which the api call to check the push status will run.
 // 		   Recommended not to go lower than 5 (seconds)
 func (r *Request) CheckPushAcceptStatus(c *sa.Client, refID string, timeout int, interval int) (*Response, error) {
-	tout := time.After(time.Duration(timeout) * time.Second)
-	tick := time.Tick(time.Duration(interval) * time.Second)
-	for {
-		select {
-		case <-tout:
-			return nil, errors.New("Request expired before response")
-		case <-tick:
-			checkResponse, err := r.Get(c, refID)
-			if err != nil {
-				return nil, err
-			}
-			switch checkResponse.Message {
-			case "ACCEPTED", "DENIED", "FAILED", "EXPIRED":
-				return checkResponse, nil
-			case "PENDING":
-				continue
-			}
-		}
+	return r.CheckPushAcceptStatusContext(context.Background(), c, refID, timeout, interval)
+}
 
+// CheckPushAcceptStatusContext :
+//	Helper function to check on the accept/deny status of a push to accept, bound to ctx. Polling stops as soon
+//	as ctx is done and the context error is returned. Unknown statuses are returned as ErrUnknownPushStatus.
+//	Use a PushWatcher to follow many requests without blocking.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the status checks.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] refId: the reference id returned by the auth end point when the type is push_accept
+//	[Required] timeout: the amount of time (in seconds) the check should run before failing.
+//	[Required] interval: the frequency (in seconds) in which the api call to check the push status will run.
+// 		   Recommended not to go lower than 5 (seconds)
+func (r *Request) CheckPushAcceptStatusContext(ctx context.Context, c *sa.Client, refID string, timeout int, interval int) (*Response, error) {
+	watcher, err := NewPushWatcher(c, time.Duration(interval)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	results, err := watcher.Watch(ctx, refID, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return nil, err
 	}
+	result := <-results
+	return result.Response, result.Err
 }
 
 // SendHelpDesk :
@@ -489,14 +374,7 @@ func (r *Request) CheckPushAcceptStatus(c *sa.Client, refID string, timeout int,
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SendHelpDesk(c *sa.Client, userID string, factorID string) (*Response, error) {
-	r.UserID = userID
-	r.ReqType = "help_desk"
-	r.FactorID = factorID
-	helpResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return helpResponse, nil
+	return NewService(c).SendHelpDesk(context.Background(), userID, factorID)
 }
 
 // buildEndpointPath :
@@ -509,6 +387,13 @@ func buildEndpointPath(refID string) string {
 	return buffer.String()
 }
 
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
 //IsSignatureValid :
 //	Helper function to validate the SecureAuth Response signature in X-SA-SIGNATURE
 // Parameters:
@@ -516,24 +401,7 @@ func buildEndpointPath(refID string) string {
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
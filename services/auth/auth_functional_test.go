@@ -4,7 +4,7 @@ import (
 	"errors"
 	"testing"
 
-	factors "github.com/jhickmansa/saidp-sdk-go/services/factors"
+	factors "github.com/secureauthcorp/saidp-sdk-go/services/factors"
 	sa "github.com/secureauthcorp/saidp-sdk-go"
 )
 
@@ -45,7 +45,7 @@ import (
 
 const (
 	uAppID       = "12345"
-	uAppKey      = "12345"
+	uAppKey      = "123456"
 	uHost        = "idp.host.com"
 	uRealm       = "secureauth1"
 	uPort        = 443
@@ -65,6 +65,7 @@ func TestAuth_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	validUserTest, err := validateUser(client)
 	if err != nil {
@@ -746,3 +747,37 @@ func makeResponseSignature(c *sa.Client, response string, timeStamp string) stri
 	sig.Write([]byte(byteData))
 	return base64.StdEncoding.EncodeToString(sig.Sum(nil))
 }
+
+func TestAuthTypes_Unit(t *testing.T) {
+	client, err := sa.NewClient(uAppID, uAppKey, uHost, uPort, uRealm, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Request{UserID: uUser, ReqType: "fingerprint"}
+	if _, err := r.Post(client); !errors.Is(err, ErrUnknownAuthType) {
+		t.Errorf("expected ErrUnknownAuthType, got %v", err)
+	}
+	if _, err := new(Request).SendOtpAdHoc(client, uUser, uEmail, "fax", false); err == nil {
+		t.Error("expected an unknown ad-hoc type to fail")
+	}
+
+	var resp Response
+	if err := json.Unmarshal([]byte(`{"status":"valid","message":""}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsValid() || resp.IsFound() {
+		t.Errorf("unexpected helpers for %+v", resp)
+	}
+	if resp.StatusValue() != StatusValid || (&Request{ReqType: "push_accept"}).Type() != TypePushAccept {
+		t.Errorf("unexpected typed accessors for %+v", resp)
+	}
+	for message, want := range map[string]PushState{"PENDING": PushPending, "ACCEPTED": PushAccepted, "EXPIRED": PushExpired} {
+		state, err := (&Response{Status: "found", Message: message}).PushState()
+		if err != nil || state != want || state.IsTerminal() == (want == PushPending) {
+			t.Errorf("%s: unexpected state %v %v", message, state, err)
+		}
+	}
+	if _, err := (&Response{Status: "found", Message: "UNKNOWN"}).PushState(); !errors.Is(err, ErrUnknownPushStatus) {
+		t.Errorf("expected ErrUnknownPushStatus, got %v", err)
+	}
+}
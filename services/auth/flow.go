@@ -0,0 +1,631 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"github.com/secureauthcorp/saidp-sdk-go/services/accesshistory"
+	"github.com/secureauthcorp/saidp-sdk-go/services/factors"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// FlowStep : state of an MFA Flow.
+type FlowStep string
+
+const (
+	// StepSelectFactor : the user is known and must pick a factor and delivery method from FlowState.Factors.
+	StepSelectFactor FlowStep = "select_factor"
+	// StepVerifyOTP : an OTP was delivered (sms, call, email, push) or is expected from an oath device.
+	StepVerifyOTP FlowStep = "verify_otp"
+	// StepAwaitPush : a push to accept request was sent; Poll until the user answers.
+	StepAwaitPush FlowStep = "await_push"
+	// StepAuthenticated : the second factor was verified and access history recorded.
+	StepAuthenticated FlowStep = "authenticated"
+	// StepFailed : the flow ended without authenticating the user; see FlowState.Reason.
+	StepFailed FlowStep = "failed"
+)
+
+// ErrInvalidStep : returned when a Flow function is called in a step that does not accept it.
+var ErrInvalidStep = errors.New("auth: flow function not allowed in the current step")
+
+// ErrInvalidFlowState : returned when an encoded FlowState was tampered with, replayed or cannot be decoded.
+var ErrInvalidFlowState = errors.New("auth: invalid flow state")
+
+// flowTokenVersion is the version of the tokens built by Flow.Encode.
+const flowTokenVersion = 1
+
+// otpMethods are the delivery methods answered with an OTP the flow compares itself.
+var otpMethods = []AuthType{TypeSMS, TypeCall, TypeEmail, TypePush}
+
+// FlowFactor :
+//	Factor of the user the flow can challenge.
+// Fields:
+//	Type: factor type from the users endpoint (phone, email, push, oath).
+//	ID: factor id (also referred to as device id).
+//	Value: masked value to display, e.g. the phone number or device name.
+//	Methods: delivery methods supported by the flow for this factor: sms, call, email, push, push_accept or oath.
+type FlowFactor struct {
+	Type    string     `json:"type"`
+	ID      string     `json:"id"`
+	Value   string     `json:"value"`
+	Methods []AuthType `json:"methods"`
+}
+
+// FlowState :
+//	Serializable state of one authentication, carried between requests with Flow.Encode and Flow.Decode or
+//	stored server side as json. It holds no OTP in clear, only a keyed MAC of it.
+// Fields:
+//	ID: random identifier of the flow, the key of its AttemptStore record.
+//	Step: the current step.
+//	UserID: the user being authenticated.
+//	IPAddress: the address recorded in access history once authenticated.
+//	Factors: the factors the user can pick from.
+//	FactorID, Method: the factor and method of the current challenge.
+//	RefID: reference id of a push to accept request.
+//	OTPSalt, OTPMAC: salt and keyed MAC of the delivered OTP.
+//	Attempts: failed OTP attempts so far, as counted by the AttemptStore when one is set.
+//	Challenges: challenges sent so far, as counted by the AttemptStore when one is set.
+//	Reason: why the last attempt or the flow failed.
+//	ExpiresAt: time after which the flow fails.
+type FlowState struct {
+	ID         string       `json:"id"`
+	Step       FlowStep     `json:"step"`
+	UserID     string       `json:"user_id"`
+	IPAddress  string       `json:"ip_address,omitempty"`
+	Factors    []FlowFactor `json:"factors,omitempty"`
+	FactorID   string       `json:"factor_id,omitempty"`
+	Method     AuthType     `json:"method,omitempty"`
+	RefID      string       `json:"reference_id,omitempty"`
+	OTPSalt    string       `json:"otp_salt,omitempty"`
+	OTPMAC     string       `json:"otp_mac,omitempty"`
+	Attempts   int          `json:"attempts"`
+	Challenges int          `json:"challenges"`
+	Reason     string       `json:"reason,omitempty"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+}
+
+// Flow :
+//	State machine driving the MFA sequence: validate the user, list the factors, let the user (or SelectFactor)
+//	pick one, send the challenge, verify the OTP or push answer and record access history. Every function
+//	updates the FlowState in place; a wrong OTP or a denied push is reported through the state (Step, Reason),
+//	errors are only returned for API failures and misuse.
+// Fields:
+//	Client: the client used for every call.
+//	MaxAttempts: failed OTP attempts before the flow fails. Defaults to 3.
+//	MaxChallenges: challenges (including resends and factor switches) before the flow fails. Defaults to 3.
+//	Timeout: lifetime of a flow. Defaults to 5 minutes.
+//	PushDetails: company, application and ip displayed on push to accept requests.
+//	Store: makes encoded tokens single use and counts attempts and challenges server side. NewFlow sets a
+//	MemoryAttemptStore, which only suits a single instance; set a shared store when several instances serve the
+//	same flows. When nil, the counts only live in the state, which must then be kept server side, and Encode
+//	is refused.
+//	SelectFactor: when set, picks the factor id and method right after Start instead of stopping at
+//	StepSelectFactor, e.g. to always use the first push device.
+//	OnStep: called after every step change, e.g. to render the next page.
+//	Now: clock used for the expiry of flows, e.g. to test expiry deterministically. Defaults to time.Now; the
+//	MemoryAttemptStore set by NewFlow follows it.
+type Flow struct {
+	Client        *sa.Client
+	MaxAttempts   int
+	MaxChallenges int
+	Timeout       time.Duration
+	PushDetails   *PushAcceptDetails
+	Store         AttemptStore
+	SelectFactor  func(ctx context.Context, state *FlowState) (factorID string, method AuthType, err error)
+	OnStep        func(ctx context.Context, from FlowStep, state *FlowState)
+	Now           func() time.Time
+	stateKey      []byte
+}
+
+// NewFlow :
+//	Helper function to create a Flow with the default limits.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] stateKey: secret of at least 32 bytes used to MAC OTPs and sign encoded states. It must be the
+//		   same on every instance serving the flow.
+// Returns:
+//	Flow: the flow, ready to Start.
+//	Error: If an error is encountered, flow will be nil and the error must be handled.
+func NewFlow(c *sa.Client, stateKey []byte) (*Flow, error) {
+	if c == nil {
+		return nil, errors.New("A client is required for creating a flow")
+	}
+	if len(stateKey) < 32 {
+		return nil, errors.New("The flow state key must be at least 32 bytes")
+	}
+	f := &Flow{
+		Client:        c,
+		MaxAttempts:   3,
+		MaxChallenges: 3,
+		Timeout:       5 * time.Minute,
+		Now:           time.Now,
+		stateKey:      append([]byte(nil), stateKey...),
+	}
+	store := NewMemoryAttemptStore()
+	store.Now = f.now
+	f.Store = store
+	return f, nil
+}
+
+// Start :
+//	Function validating the user and listing the factors the flow can use. The state ends in StepSelectFactor,
+//	in the step of the challenge sent when SelectFactor is set, or in StepFailed when the user is not found or
+//	has no usable factor.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the calls.
+//	[Required] userID: the user to authenticate.
+//	ipAddress: the address of the user, recorded in access history once authenticated.
+// Returns:
+//	FlowState: the new state.
+//	Error: If an error is encountered, state will be nil and the error must be handled.
+func (f *Flow) Start(ctx context.Context, userID string, ipAddress string) (*FlowState, error) {
+	if userID == "" {
+		return nil, errors.New("A userID is required for starting a flow")
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	state := &FlowState{ID: id, UserID: userID, IPAddress: ipAddress, ExpiresAt: f.now().Add(f.timeout())}
+	userResponse, err := (&Request{UserID: userID, ReqType: string(TypeUserID)}).PostContext(ctx, f.Client)
+	if err != nil {
+		return nil, err
+	}
+	if !userResponse.IsFound() {
+		f.fail(ctx, state, userResponse.Message)
+		return state, nil
+	}
+	factorsResponse, err := new(factors.Request).GetContext(ctx, f.Client, userID)
+	if err != nil {
+		return nil, err
+	}
+	state.Factors = flowFactors(factorsResponse.Factors)
+	if len(state.Factors) == 0 {
+		f.fail(ctx, state, "No factor available for the user.")
+		return state, nil
+	}
+	f.move(ctx, state, StepSelectFactor)
+	if f.SelectFactor == nil {
+		return state, nil
+	}
+	factorID, method, err := f.SelectFactor(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Challenge(ctx, state, factorID, method); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Challenge :
+//	Function sending the challenge of a factor: an OTP by sms, call, email or push, a push to accept request,
+//	or nothing for oath devices. Allowed while selecting a factor and to resend or switch factors while waiting
+//	for an answer.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the call.
+//	[Required] state: the flow state.
+//	[Required] factorID: id of one of state.Factors.
+//	[Required] method: one of the Methods of the factor.
+// Returns:
+//	Error: ErrInvalidStep, an error for an unknown factor or method, or the API error.
+func (f *Flow) Challenge(ctx context.Context, state *FlowState, factorID string, method AuthType) error {
+	if ok, err := f.expect(ctx, state, StepSelectFactor, StepVerifyOTP, StepAwaitPush); !ok {
+		return err
+	}
+	if !state.supports(factorID, method) {
+		return fmt.Errorf("Factor %q does not support %q", factorID, method)
+	}
+	challenges := state.Challenges + 1
+	if f.Store != nil {
+		counted, err := f.Store.AddChallenge(ctx, state.ID, state.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		challenges = counted
+	}
+	if challenges > f.maxChallenges() {
+		f.fail(ctx, state, "Too many challenges.")
+		return nil
+	}
+	state.Challenges = challenges
+	state.FactorID, state.Method, state.RefID, state.OTPSalt, state.OTPMAC = factorID, method, "", "", ""
+
+	switch method {
+	case TypeOath:
+		f.move(ctx, state, StepVerifyOTP)
+		return nil
+	case TypePushAccept:
+		r := &Request{UserID: state.UserID, ReqType: string(method), FactorID: factorID, PushDetails: f.PushDetails}
+		resp, err := r.PostContext(ctx, f.Client)
+		if err != nil {
+			return err
+		}
+		if !resp.IsValid() || resp.RefID == "" {
+			f.fail(ctx, state, resp.Message)
+			return nil
+		}
+		state.RefID = resp.RefID
+		f.move(ctx, state, StepAwaitPush)
+		return nil
+	}
+	resp, err := (&Request{UserID: state.UserID, ReqType: string(method), FactorID: factorID}).PostContext(ctx, f.Client)
+	if err != nil {
+		return err
+	}
+	if !resp.IsValid() || resp.OTP == "" {
+		f.fail(ctx, state, resp.Message)
+		return nil
+	}
+	salt, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	state.OTPSalt = salt
+	state.OTPMAC = f.otpMAC(state.OTPSalt, resp.OTP)
+	f.move(ctx, state, StepVerifyOTP)
+	return nil
+}
+
+// Verify :
+//	Function checking the OTP entered by the user. OTPs delivered by the IdP are compared locally in constant
+//	time; oath OTPs are validated by the IdP. Every attempt is counted (in the Store when set) before the OTP is
+//	checked, and no OTP is checked once MaxAttempts is reached. A wrong OTP leaves the state in StepVerifyOTP
+//	with Reason set until MaxAttempts is reached.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the calls.
+//	[Required] state: the flow state.
+//	[Required] otp: the OTP entered by the user.
+// Returns:
+//	Error: ErrInvalidStep or the API error.
+func (f *Flow) Verify(ctx context.Context, state *FlowState, otp string) error {
+	if ok, err := f.expect(ctx, state, StepVerifyOTP); !ok {
+		return err
+	}
+	attempts := state.Attempts + 1
+	if f.Store != nil {
+		counted, err := f.Store.AddAttempt(ctx, state.ID, state.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		attempts = counted
+	}
+	if attempts > f.maxAttempts() {
+		state.Attempts = attempts - 1
+		f.fail(ctx, state, "Too many failed attempts.")
+		return nil
+	}
+	valid := false
+	reason := "OTP is invalid."
+	if state.Method == TypeOath {
+		resp, err := (&Request{UserID: state.UserID, ReqType: string(TypeOath), Token: otp, FactorID: state.FactorID}).PostContext(ctx, f.Client)
+		if err != nil {
+			return err
+		}
+		valid = resp.IsValid()
+		if resp.Message != "" {
+			reason = resp.Message
+		}
+	} else {
+		valid = hmac.Equal([]byte(f.otpMAC(state.OTPSalt, strings.TrimSpace(otp))), []byte(state.OTPMAC))
+	}
+	if valid {
+		state.Attempts = attempts - 1
+		return f.complete(ctx, state)
+	}
+	state.Attempts = attempts
+	state.Reason = reason
+	if state.Attempts >= f.maxAttempts() {
+		f.fail(ctx, state, "Too many failed attempts.")
+	}
+	return nil
+}
+
+// Poll :
+//	Function checking once whether the user answered the push to accept request. The state stays in
+//	StepAwaitPush while the request is pending.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the calls.
+//	[Required] state: the flow state.
+// Returns:
+//	Error: ErrInvalidStep or the API error.
+func (f *Flow) Poll(ctx context.Context, state *FlowState) error {
+	if ok, err := f.expect(ctx, state, StepAwaitPush); !ok {
+		return err
+	}
+	resp, err := new(Request).GetContext(ctx, f.Client, state.RefID)
+	if err != nil {
+		return err
+	}
+	pushState, err := resp.PushState()
+	if err != nil {
+		return err
+	}
+	switch {
+	case pushState == PushAccepted:
+		return f.complete(ctx, state)
+	case pushState.IsTerminal():
+		f.fail(ctx, state, "Push to accept request "+strings.ToLower(string(pushState))+".")
+	}
+	return nil
+}
+
+// flowToken :
+//	non-exportable content of a token built by Encode: the state bound to the one-time nonce issued in the Store.
+type flowToken struct {
+	Version int        `json:"v"`
+	Nonce   string     `json:"n"`
+	State   *FlowState `json:"s"`
+}
+
+// Encode :
+//	Function serializing a state into an opaque, signed, single use token, e.g. for a hidden form field or a
+//	cookie. The token is bound to a nonce recorded in the Store, which replaces any token encoded earlier for
+//	the same flow, so only the latest token of a flow can be decoded, and only once.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the Store call.
+//	[Required] state: the flow state.
+// Returns:
+//	string: the url safe token.
+//	Error: If the state cannot be serialized, no Store is set or the Store fails.
+func (f *Flow) Encode(ctx context.Context, state *FlowState) (string, error) {
+	if f.Store == nil {
+		return "", errors.New("An AttemptStore is required to encode flow states")
+	}
+	if state == nil || state.ID == "" {
+		return "", errors.New("A flow state started by Start is required")
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(flowToken{Version: flowTokenVersion, Nonce: nonce, State: state})
+	if err != nil {
+		return "", err
+	}
+	if err := f.Store.Issue(ctx, state.ID, nonce, state.ExpiresAt); err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(content)
+	return payload + "." + f.sign(payload), nil
+}
+
+// Decode :
+//	Function restoring a state from a token built by Encode, rejecting tokens that were modified, replaced by a
+//	later Encode or already decoded. Encode the state again before handing it back to the user.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the Store call.
+//	[Required] token: the token.
+// Returns:
+//	FlowState: the restored state.
+//	Error: ErrInvalidFlowState when the token is not valid, or the Store error.
+func (f *Flow) Decode(ctx context.Context, token string) (*FlowState, error) {
+	if f.Store == nil {
+		return nil, errors.New("An AttemptStore is required to decode flow states")
+	}
+	i := strings.LastIndex(token, ".")
+	if i < 0 || !hmac.Equal([]byte(f.sign(token[:i])), []byte(token[i+1:])) {
+		return nil, ErrInvalidFlowState
+	}
+	content, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return nil, ErrInvalidFlowState
+	}
+	decoded := new(flowToken)
+	if err := json.Unmarshal(content, decoded); err != nil || decoded.Version != flowTokenVersion || decoded.State == nil {
+		return nil, ErrInvalidFlowState
+	}
+	redeemed, err := f.Store.Redeem(ctx, decoded.State.ID, decoded.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if !redeemed {
+		return nil, fmt.Errorf("%w: token was already used or replaced", ErrInvalidFlowState)
+	}
+	return decoded.State, nil
+}
+
+// complete :
+//	non-exportable helper recording access history and ending the flow authenticated.
+func (f *Flow) complete(ctx context.Context, state *FlowState) error {
+	if state.IPAddress != "" {
+		r := &accesshistory.Request{UserID: state.UserID, IPAddress: state.IPAddress}
+		if _, err := r.PostContext(ctx, f.Client); err != nil {
+			return err
+		}
+	}
+	state.Reason = ""
+	state.OTPSalt, state.OTPMAC = "", ""
+	f.move(ctx, state, StepAuthenticated)
+	return nil
+}
+
+// expect :
+//	non-exportable helper checking the step of a state. Expired flows are failed and must not proceed.
+func (f *Flow) expect(ctx context.Context, state *FlowState, steps ...FlowStep) (bool, error) {
+	if state == nil {
+		return false, errors.New("A flow state is required")
+	}
+	if !containsStep(steps, state.Step) {
+		return false, fmt.Errorf("%w: %s", ErrInvalidStep, state.Step)
+	}
+	if f.now().After(state.ExpiresAt) {
+		f.fail(ctx, state, "Authentication expired.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// containsStep :
+//	non-exportable helper reporting whether steps holds step.
+func containsStep(steps []FlowStep, step FlowStep) bool {
+	for _, s := range steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// fail :
+//	non-exportable helper ending the flow.
+func (f *Flow) fail(ctx context.Context, state *FlowState, reason string) {
+	state.Reason = reason
+	state.OTPSalt, state.OTPMAC = "", ""
+	f.move(ctx, state, StepFailed)
+}
+
+// move :
+//	non-exportable helper changing the step of a state and notifying OnStep.
+func (f *Flow) move(ctx context.Context, state *FlowState, step FlowStep) {
+	from := state.Step
+	state.Step = step
+	if f.OnStep != nil {
+		f.OnStep(ctx, from, state)
+	}
+}
+
+// otpMAC :
+//	non-exportable helper computing the keyed MAC stored instead of a delivered OTP.
+func (f *Flow) otpMAC(salt string, otp string) string {
+	return f.sign(salt + "\n" + otp)
+}
+
+// sign :
+//	non-exportable helper computing a HMAC-SHA256 with the state key.
+func (f *Flow) sign(data string) string {
+	mac := hmac.New(sha256.New, f.stateKey)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomHex :
+//	non-exportable helper returning n random bytes, hex encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// timeout :
+//	non-exportable helper returning Timeout or its default.
+func (f *Flow) timeout() time.Duration {
+	if f.Timeout <= 0 {
+		return 5 * time.Minute
+	}
+	return f.Timeout
+}
+
+// now :
+//	non-exportable helper returning the current time from Now, or time.Now when it is not set.
+func (f *Flow) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+	return f.Now()
+}
+
+// maxAttempts :
+//	non-exportable helper returning MaxAttempts or its default.
+func (f *Flow) maxAttempts() int {
+	if f.MaxAttempts <= 0 {
+		return 3
+	}
+	return f.MaxAttempts
+}
+
+// maxChallenges :
+//	non-exportable helper returning MaxChallenges or its default.
+func (f *Flow) maxChallenges() int {
+	if f.MaxChallenges <= 0 {
+		return 3
+	}
+	return f.MaxChallenges
+}
+
+// supports :
+//	non-exportable helper reporting whether a factor of the state supports a method.
+func (s *FlowState) supports(factorID string, method AuthType) bool {
+	for _, factor := range s.Factors {
+		if factor.ID == factorID {
+			return containsType(factor.Methods, method)
+		}
+	}
+	return false
+}
+
+// flowFactors :
+//	non-exportable helper mapping the factors of the users endpoint to the methods the flow can drive.
+func flowFactors(userFactors factors.Factors) []FlowFactor {
+	var flow []FlowFactor
+	for _, factor := range userFactors {
+		var methods []AuthType
+		switch {
+		case factor.FactorType == "oath":
+			methods = []AuthType{TypeOath}
+		case len(factor.Capabilities) > 0:
+			for _, capability := range factor.Capabilities {
+				if method := AuthType(capability); method == TypePushAccept || containsType(otpMethods, method) {
+					methods = append(methods, method)
+				}
+			}
+		case factor.FactorType == "email":
+			methods = []AuthType{TypeEmail}
+		case factor.FactorType == "phone":
+			methods = []AuthType{TypeSMS, TypeCall}
+		}
+		if len(methods) > 0 && factor.ID != "" {
+			flow = append(flow, FlowFactor{Type: factor.FactorType, ID: factor.ID, Value: factor.Value, Methods: methods})
+		}
+	}
+	return flow
+}
+
+// containsType :
+//	non-exportable helper reporting whether list holds value.
+func containsType(list []AuthType, value AuthType) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
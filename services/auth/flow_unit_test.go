@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secureauthcorp/saidp-sdk-go/saidptest"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+var uStateKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newFlowServer(t *testing.T) (*saidptest.Server, *Flow) {
+	server := saidptest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(saidptest.User{
+		ID:   uUser,
+		OATH: "246810",
+		Factors: []saidptest.Factor{
+			{Type: "phone", ID: "Phone1", Value: "xxx-xxx-1234", Capabilities: []string{"sms", "call"}},
+			{Type: "push", ID: "device1", Value: "iPhone", Capabilities: []string{"push", "push_accept"}},
+			{Type: "oath", ID: uOathDevice, Value: "Authenticator"},
+			{Type: "kbq", ID: "kbq1", Value: "First car"},
+		},
+	})
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow, err := NewFlow(client, uStateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, flow
+}
+
+func TestFlowOTP_Unit(t *testing.T) {
+	ctx := context.Background()
+	server, flow := newFlowServer(t)
+	var steps []string
+	flow.OnStep = func(ctx context.Context, from FlowStep, state *FlowState) {
+		steps = append(steps, string(state.Step))
+	}
+
+	state, err := flow.Start(ctx, uUser, uUserIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Step != StepSelectFactor || len(state.Factors) != 3 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if err := flow.Verify(ctx, state, "000000"); !errors.Is(err, ErrInvalidStep) {
+		t.Errorf("expected ErrInvalidStep, got %v", err)
+	}
+	if err := flow.Challenge(ctx, state, "Phone1", "push_accept"); err == nil {
+		t.Error("expected an unsupported method to fail")
+	}
+	if err := flow.Challenge(ctx, state, "Phone1", "sms"); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := server.User(uUser)
+	if state.Step != StepVerifyOTP || state.OTPMAC == "" || strings.Contains(state.OTPMAC, u.LastOTP) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	// Resume from an encoded state, as a second stateless http request would.
+	token, err := flow.Encode(ctx, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(token, u.LastOTP) {
+		t.Error("expected the encoded state not to hold the OTP")
+	}
+	if _, err := flow.Decode(ctx, strings.Replace(token, "a", "b", 1)); !errors.Is(err, ErrInvalidFlowState) {
+		t.Errorf("expected ErrInvalidFlowState, got %v", err)
+	}
+	resumed, err := flow.Decode(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Verify(ctx, resumed, "000000"); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Step != StepVerifyOTP || resumed.Attempts != 1 || resumed.Reason == "" {
+		t.Fatalf("unexpected state %+v", resumed)
+	}
+	if err := flow.Verify(ctx, resumed, u.LastOTP); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Step != StepAuthenticated {
+		t.Fatalf("unexpected state %+v", resumed)
+	}
+	recorded := false
+	for _, r := range server.Requests() {
+		recorded = recorded || (r.Endpoint == "/api/v1/accesshistory" && strings.Contains(string(r.Body), uUserIP))
+	}
+	if !recorded {
+		t.Error("expected access history to be recorded")
+	}
+	if strings.Join(steps, ",") != "select_factor,verify_otp,authenticated" {
+		t.Errorf("unexpected steps %v", steps)
+	}
+}
+
+func TestFlowPush_Unit(t *testing.T) {
+	ctx := context.Background()
+	server, flow := newFlowServer(t)
+	server.ScriptPush(uUser, saidptest.PushPending, saidptest.PushAccepted)
+	flow.SelectFactor = func(ctx context.Context, state *FlowState) (string, AuthType, error) {
+		return "device1", TypePushAccept, nil
+	}
+	flow.PushDetails = &PushAcceptDetails{CompanyName: "Acme", AppDesc: "Portal", EnduserIP: uUserIP}
+
+	state, err := flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Step != StepAwaitPush || state.RefID == "" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if err := flow.Poll(ctx, state); err != nil || state.Step != StepAwaitPush {
+		t.Fatalf("expected a pending push to keep waiting, got %v %+v", err, state)
+	}
+	if err := flow.Poll(ctx, state); err != nil || state.Step != StepAuthenticated {
+		t.Fatalf("expected an accepted push to authenticate, got %v %+v", err, state)
+	}
+
+	server.ScriptPush(uUser, saidptest.PushDenied)
+	state, err = flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Poll(ctx, state); err != nil || state.Step != StepFailed {
+		t.Fatalf("expected a denied push to fail, got %v %+v", err, state)
+	}
+}
+
+func TestFlowLimits_Unit(t *testing.T) {
+	ctx := context.Background()
+	_, flow := newFlowServer(t)
+	if _, err := NewFlow(flow.Client, []byte("short")); err == nil {
+		t.Error("expected a short state key to fail")
+	}
+
+	state, err := flow.Start(ctx, "unknown", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Step != StepFailed || state.Reason == "" {
+		t.Errorf("expected unknown users to fail, got %+v", state)
+	}
+
+	state, err = flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Challenge(ctx, state, uOathDevice, "oath"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < flow.MaxAttempts; i++ {
+		if err := flow.Verify(ctx, state, "000000"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Step != StepFailed || state.Attempts != flow.MaxAttempts {
+		t.Errorf("expected the flow to fail after MaxAttempts, got %+v", state)
+	}
+
+	state, err = flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Challenge(ctx, state, uOathDevice, "oath"); err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Verify(ctx, state, "246810"); err != nil || state.Step != StepAuthenticated {
+		t.Errorf("expected a valid oath OTP to authenticate, got %v %+v", err, state)
+	}
+
+	state, err = flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= flow.MaxChallenges; i++ {
+		if err := flow.Challenge(ctx, state, "Phone1", "call"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Step != StepFailed {
+		t.Errorf("expected the flow to fail after MaxChallenges, got %+v", state)
+	}
+
+	now := time.Date(2020, time.January, 2, 15, 4, 5, 0, time.UTC)
+	flow.Now = func() time.Time { return now }
+	state, err = flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.ExpiresAt.Equal(now.Add(flow.Timeout)) {
+		t.Errorf("expected the flow to expire Timeout after the flow clock, got %v", state.ExpiresAt)
+	}
+	token, err := flow.Encode(ctx, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(flow.Timeout + time.Second)
+	if _, err := flow.Decode(ctx, token); !errors.Is(err, ErrInvalidFlowState) {
+		t.Errorf("expected the store to refuse a token of an expired flow, got %v", err)
+	}
+	if err := flow.Challenge(ctx, state, "Phone1", "sms"); err != nil || state.Step != StepFailed {
+		t.Errorf("expected an expired flow to fail, got %v %+v", err, state)
+	}
+}
+
+func TestFlowReplay_Unit(t *testing.T) {
+	ctx := context.Background()
+	server, flow := newFlowServer(t)
+	state, err := flow.Start(ctx, uUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Challenge(ctx, state, "Phone1", "sms"); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := server.User(uUser)
+
+	first, err := flow.Encode(ctx, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := flow.Decode(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Verify(ctx, resumed, "000000"); err != nil {
+		t.Fatal(err)
+	}
+	second, err := flow.Encode(ctx, resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := flow.Decode(ctx, first); !errors.Is(err, ErrInvalidFlowState) {
+		t.Errorf("expected a replayed token to be rejected, got %v", err)
+	}
+	if _, err := flow.Decode(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := flow.Decode(ctx, second); !errors.Is(err, ErrInvalidFlowState) {
+		t.Errorf("expected a token to be decoded only once, got %v", err)
+	}
+
+	// Copies of a state, e.g. restored from a stale server side record, share the counts of the Store.
+	copies := make([]FlowState, flow.MaxAttempts)
+	for i := range copies {
+		copies[i] = *resumed
+	}
+	for i := range copies[:flow.MaxAttempts-1] {
+		if err := flow.Verify(ctx, &copies[i], "000000"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if copies[flow.MaxAttempts-2].Step != StepFailed {
+		t.Errorf("expected the attempts of every copy to count, got %+v", copies[flow.MaxAttempts-2])
+	}
+	last := &copies[flow.MaxAttempts-1]
+	if err := flow.Verify(ctx, last, u.LastOTP); err != nil {
+		t.Fatal(err)
+	}
+	if last.Step != StepFailed {
+		t.Errorf("expected no OTP to be checked once MaxAttempts is reached, got %+v", last)
+	}
+
+	flow.Store = nil
+	if _, err := flow.Encode(ctx, state); err == nil {
+		t.Error("expected Encode without a store to fail")
+	}
+}
@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrPushTimeout : delivered when a push to accept request is still pending when its timeout elapses.
+var ErrPushTimeout = errors.New("auth: push request expired before response")
+
+// ErrUnknownPushStatus : delivered when the IdP answers a status check with a message that is not a known status.
+var ErrUnknownPushStatus = errors.New("auth: unknown push status")
+
+// PushResult :
+//	Terminal outcome of a watched push to accept request.
+// Fields:
+//	RefID: the reference id that was watched.
+//	Response: the status check response; Message is ACCEPTED, DENIED, FAILED or EXPIRED. Nil when Err is set.
+//	Err: ErrPushTimeout (wrapping the last failed status check, if any), ErrUnknownPushStatus, the context error or
+//	an API error that would fail the same way again (e.g. ErrUnauthorized).
+type PushResult struct {
+	RefID    string
+	Response *Response
+	Err      error
+}
+
+// PushWatcher :
+//	Non-blocking watcher of push to accept requests. Any number of reference ids can be watched at once; a single
+//	scheduler starts the polls of the ones that are due, at most concurrency at a time, without waiting for them,
+//	and stops on its own when nothing is watched. Every poll is bounded by the timeout of its watch, so a hung
+//	status check cannot delay the others. Status checks failing with a transport error, a 5xx or a 429 response are
+//	tried again at the next interval until the timeout. Safe for concurrent use.
+type PushWatcher struct {
+	client      *sa.Client
+	interval    time.Duration
+	concurrency int
+	mu          sync.Mutex
+	watches     map[string]*pushWatch
+	inFlight    int
+	running     bool
+	wake        chan struct{}
+}
+
+// pushWatch :
+//	non-exportable state of one watched reference id.
+type pushWatch struct {
+	ctx      context.Context
+	deadline time.Time
+	next     time.Time
+	polling  bool
+	lastErr  error
+	deliver  func(PushResult)
+	stop     func() bool
+}
+
+// NewPushWatcher :
+//	Helper function to create a PushWatcher.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] interval: time between two status checks of the same reference id. Recommended not to go lower
+//		   than 5 seconds.
+// Returns:
+//	PushWatcher: the watcher.
+//	Error: If an error is encountered, watcher will be nil and the error must be handled.
+func NewPushWatcher(c *sa.Client, interval time.Duration) (*PushWatcher, error) {
+	if c == nil {
+		return nil, errors.New("A client is required for creating a push watcher")
+	}
+	if interval <= 0 {
+		return nil, errors.New("The polling interval must be positive")
+	}
+	return &PushWatcher{
+		client:      c,
+		interval:    interval,
+		concurrency: 8,
+		watches:     make(map[string]*pushWatch),
+		wake:        make(chan struct{}, 1),
+	}, nil
+}
+
+// Watch :
+//	Function watching a push to accept request and delivering its terminal result on the returned channel,
+//	which receives exactly one PushResult and is then closed.
+// Parameters:
+//	[Required] ctx: context bounding the watch; its error is delivered when it is done first.
+//	[Required] refID: the reference id returned by the auth endpoint for a push_accept request.
+//	[Required] timeout: time after which ErrPushTimeout is delivered.
+// Returns:
+//	chan PushResult: channel receiving the result.
+//	Error: If ctx is nil, the timeout is not positive, or the reference id is empty or already watched.
+func (w *PushWatcher) Watch(ctx context.Context, refID string, timeout time.Duration) (<-chan PushResult, error) {
+	results := make(chan PushResult, 1)
+	err := w.WatchFunc(ctx, refID, timeout, func(result PushResult) {
+		results <- result
+		close(results)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// WatchFunc :
+//	Function watching a push to accept request and calling fn once with its terminal result. fn is called from
+//	a goroutine of the watcher and must not block.
+// Parameters:
+//	[Required] ctx: context bounding the watch; its error is delivered when it is done first.
+//	[Required] refID: the reference id returned by the auth endpoint for a push_accept request.
+//	[Required] timeout: time after which ErrPushTimeout is delivered.
+//	[Required] fn: callback receiving the result.
+// Returns:
+//	Error: If ctx or fn is nil, the timeout is not positive, or the reference id is empty or already watched.
+func (w *PushWatcher) WatchFunc(ctx context.Context, refID string, timeout time.Duration, fn func(PushResult)) error {
+	if ctx == nil {
+		return errors.New("A non-nil context is required")
+	}
+	if fn == nil {
+		return errors.New("A non-nil callback is required")
+	}
+	if refID == "" {
+		return errors.New("A reference id is required")
+	}
+	if timeout <= 0 {
+		return errors.New("A positive timeout is required")
+	}
+	now := time.Now()
+	watch := &pushWatch{ctx: ctx, deadline: now.Add(timeout), next: now.Add(w.interval), deliver: fn}
+
+	w.mu.Lock()
+	if _, ok := w.watches[refID]; ok {
+		w.mu.Unlock()
+		return fmt.Errorf("Reference id %q is already watched", refID)
+	}
+	// The callback takes the lock, so it cannot run before the watch is registered.
+	watch.stop = context.AfterFunc(ctx, func() {
+		w.finish(refID, PushResult{RefID: refID, Err: ctx.Err()})
+	})
+	w.watches[refID] = watch
+	start := !w.running
+	w.running = true
+	w.mu.Unlock()
+
+	if start {
+		go w.run()
+	} else {
+		w.signal()
+	}
+	return nil
+}
+
+// Watching :
+//	Function returning the number of reference ids being watched.
+// Returns:
+//	int: the number of pending watches.
+func (w *PushWatcher) Watching() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.watches)
+}
+
+// run :
+//	non-exportable scheduler loop polling the due reference ids until nothing is watched.
+func (w *PushWatcher) run() {
+	for {
+		w.mu.Lock()
+		if len(w.watches) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		// Watches being polled, or waiting for a free slot, are woken up by signal once a poll ends.
+		var wakeAt time.Time
+		canPoll := w.inFlight < w.concurrency
+		for _, watch := range w.watches {
+			at := watch.deadline
+			if canPoll && !watch.polling && watch.next.Before(at) {
+				at = watch.next
+			}
+			if wakeAt.IsZero() || at.Before(wakeAt) {
+				wakeAt = at
+			}
+		}
+		w.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(wakeAt))
+		select {
+		case <-timer.C:
+		case <-w.wake:
+			timer.Stop()
+		}
+		w.pollDue()
+	}
+}
+
+// pollDue :
+//	non-exportable helper expiring the watches past their deadline and starting the polls of the reference ids
+//	that are due, as long as fewer than concurrency polls are in flight. It does not wait for the polls.
+func (w *PushWatcher) pollDue() {
+	now := time.Now()
+	expired := make(map[string]error)
+	w.mu.Lock()
+	for refID, watch := range w.watches {
+		switch {
+		case !now.Before(watch.deadline):
+			expired[refID] = timeoutError(watch.lastErr)
+		case !watch.polling && !now.Before(watch.next) && w.inFlight < w.concurrency:
+			watch.polling = true
+			w.inFlight++
+			go w.poll(refID, watch)
+		}
+	}
+	w.mu.Unlock()
+	for refID, err := range expired {
+		w.finish(refID, PushResult{RefID: refID, Err: err})
+	}
+}
+
+// poll :
+//	non-exportable helper checking the status of one reference id once, bounded by the deadline of its watch.
+func (w *PushWatcher) poll(refID string, watch *pushWatch) {
+	defer func() {
+		w.mu.Lock()
+		watch.polling = false
+		w.inFlight--
+		w.mu.Unlock()
+		w.signal()
+	}()
+	ctx, cancel := context.WithDeadline(watch.ctx, watch.deadline)
+	defer cancel()
+	resp, err := new(Request).GetContext(ctx, w.client, refID)
+	if err != nil {
+		w.mu.Lock()
+		lastErr := watch.lastErr
+		w.mu.Unlock()
+		switch {
+		case watch.ctx.Err() != nil:
+			w.finish(refID, PushResult{RefID: refID, Err: watch.ctx.Err()})
+		case ctx.Err() != nil:
+			w.finish(refID, PushResult{RefID: refID, Err: timeoutError(lastErr)})
+		case retryablePoll(err):
+			w.mu.Lock()
+			watch.lastErr = err
+			watch.next = time.Now().Add(w.interval)
+			w.mu.Unlock()
+		default:
+			w.finish(refID, PushResult{RefID: refID, Err: err})
+		}
+		return
+	}
+	state, err := resp.PushState()
+	switch {
+	case err != nil:
+		w.finish(refID, PushResult{RefID: refID, Err: err})
+	case state.IsTerminal():
+		w.finish(refID, PushResult{RefID: refID, Response: resp})
+	default:
+		w.mu.Lock()
+		watch.next = time.Now().Add(w.interval)
+		w.mu.Unlock()
+	}
+}
+
+// retryablePoll :
+//	non-exportable helper reporting if a failed status check is worth trying again at the next interval: transport
+//	errors, 5xx and 429 responses.
+func retryablePoll(err error) bool {
+	var httpErr *sa.HTTPError
+	if !errors.As(err, &httpErr) {
+		return true
+	}
+	return errors.Is(err, sa.ErrServer) || errors.Is(err, sa.ErrThrottled)
+}
+
+// timeoutError :
+//	non-exportable helper returning ErrPushTimeout, wrapping the last failed status check of the watch, if any.
+func timeoutError(lastErr error) error {
+	if lastErr == nil {
+		return ErrPushTimeout
+	}
+	return fmt.Errorf("%w: last status check failed: %v", ErrPushTimeout, lastErr)
+}
+
+// finish :
+//	non-exportable helper delivering the result of a watch once and forgetting it.
+func (w *PushWatcher) finish(refID string, result PushResult) {
+	w.mu.Lock()
+	watch, ok := w.watches[refID]
+	if ok {
+		delete(w.watches, refID)
+	}
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+	watch.stop()
+	watch.deliver(result)
+	w.signal()
+}
+
+// signal :
+//	non-exportable helper waking the scheduler to recompute its next deadline.
+func (w *PushWatcher) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	"github.com/secureauthcorp/saidp-sdk-go/saidptest"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestPushWatcher_Unit(t *testing.T) {
+	server := saidptest.NewServer()
+	defer server.Close()
+	push := []saidptest.Factor{{Type: "push", ID: "device1", Value: "iPhone", Capabilities: []string{"push_accept"}}}
+	server.AddUser(saidptest.User{ID: "alice", Factors: push})
+	server.AddUser(saidptest.User{ID: "bob", Factors: push})
+	server.AddUser(saidptest.User{ID: "carol", Factors: push})
+	server.ScriptPush("alice", saidptest.PushPending, saidptest.PushPending, saidptest.PushAccepted)
+	server.ScriptPush("bob", saidptest.PushDenied)
+	server.ScriptPush("carol", saidptest.PushPending)
+	server.Handle(http.MethodGet, "/api/v1/auth/unknown-ref", func(r *saidptest.Request) (int, interface{}) {
+		return http.StatusOK, map[string]string{"status": "valid", "message": "SOMETHING_NEW"}
+	})
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := make(map[string]string)
+	for _, user := range []string{"alice", "bob", "carol"} {
+		resp, err := new(Request).SendPushAccept(client, user, "device1", "Acme", "Portal", uUserIP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs[user] = resp.RefID
+	}
+
+	watcher, err := NewPushWatcher(client, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	alice, err := watcher.Watch(ctx, refs["alice"], time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watcher.Watch(ctx, refs["alice"], time.Second); err == nil {
+		t.Error("expected watching the same reference id twice to fail")
+	}
+	bob := make(chan PushResult, 1)
+	if err := watcher.WatchFunc(ctx, refs["bob"], time.Second, func(result PushResult) { bob <- result }); err != nil {
+		t.Fatal(err)
+	}
+	carol, err := watcher.Watch(ctx, refs["carol"], 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := watcher.Watch(ctx, "unknown-ref", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceledCtx, cancel := context.WithCancel(ctx)
+	canceled, err := watcher.Watch(canceledCtx, "never-answered", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	if result := <-alice; result.Err != nil || result.Response.Message != saidptest.PushAccepted || result.RefID != refs["alice"] {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if _, open := <-alice; open {
+		t.Error("expected the result channel to be closed")
+	}
+	if result := <-bob; result.Err != nil || result.Response.Message != saidptest.PushDenied {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result := <-carol; !errors.Is(result.Err, ErrPushTimeout) {
+		t.Errorf("expected ErrPushTimeout, got %+v", result)
+	}
+	if result := <-unknown; !errors.Is(result.Err, ErrUnknownPushStatus) {
+		t.Errorf("expected ErrUnknownPushStatus, got %+v", result)
+	}
+	if result := <-canceled; !errors.Is(result.Err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %+v", result)
+	}
+	if n := watcher.Watching(); n != 0 {
+		t.Errorf("expected no pending watches, got %d", n)
+	}
+	if _, err := NewPushWatcher(client, 0); err == nil {
+		t.Error("expected a zero interval to fail")
+	}
+}
+
+func TestPushWatcherHungPoll_Unit(t *testing.T) {
+	server := saidptest.NewServer()
+	defer server.Close()
+	release := make(chan struct{})
+	defer close(release)
+	server.Handle(http.MethodGet, "/api/v1/auth/hung-ref", func(r *saidptest.Request) (int, interface{}) {
+		<-release
+		return http.StatusOK, map[string]string{"status": "valid", "message": saidptest.PushAccepted}
+	})
+	server.AddUser(saidptest.User{ID: "alice", Factors: []saidptest.Factor{{Type: "push", ID: "device1", Capabilities: []string{"push_accept"}}}})
+	server.ScriptPush("alice", saidptest.PushPending, saidptest.PushAccepted)
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := new(Request).SendPushAccept(client, "alice", "device1", "Acme", "Portal", uUserIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewPushWatcher(client, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	hung, err := watcher.Watch(ctx, "hung-ref", 500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := watcher.Watch(ctx, resp.RefID, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case result := <-alice:
+		if result.Err != nil || result.Response.Message != saidptest.PushAccepted {
+			t.Errorf("unexpected result %+v", result)
+		}
+	case <-hung:
+		t.Fatal("expected the other watches to be polled while a status check hangs")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the other watches to be polled while a status check hangs")
+	}
+	select {
+	case result := <-hung:
+		if !errors.Is(result.Err, ErrPushTimeout) {
+			t.Errorf("expected ErrPushTimeout, got %+v", result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the hung status check to be bounded by the watch timeout")
+	}
+}
+
+func TestPushWatcherRetries_Unit(t *testing.T) {
+	server := saidptest.NewServer()
+	defer server.Close()
+	var flakyChecks int32
+	server.Handle(http.MethodGet, "/api/v1/auth/flaky-ref", func(r *saidptest.Request) (int, interface{}) {
+		if atomic.AddInt32(&flakyChecks, 1) <= 2 {
+			return http.StatusServiceUnavailable, map[string]string{"status": "error", "message": "Service unavailable"}
+		}
+		return http.StatusOK, map[string]string{"status": "valid", "message": saidptest.PushAccepted}
+	})
+	server.Handle(http.MethodGet, "/api/v1/auth/down-ref", func(r *saidptest.Request) (int, interface{}) {
+		return http.StatusBadGateway, map[string]string{"status": "error", "message": "Bad gateway"}
+	})
+	server.Handle(http.MethodGet, "/api/v1/auth/denied-ref", func(r *saidptest.Request) (int, interface{}) {
+		return http.StatusUnauthorized, map[string]string{"status": "invalid", "message": "Unauthorized"}
+	})
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := NewPushWatcher(client, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	flaky, err := watcher.Watch(ctx, "flaky-ref", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, err := watcher.Watch(ctx, "down-ref", 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied, err := watcher.Watch(ctx, "denied-ref", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := <-flaky; result.Err != nil || result.Response.Message != saidptest.PushAccepted {
+		t.Errorf("expected polling to go on after 503 responses, got %+v", result)
+	}
+	if result := <-down; !errors.Is(result.Err, ErrPushTimeout) || !strings.Contains(result.Err.Error(), "502") {
+		t.Errorf("expected ErrPushTimeout wrapping the last failed check, got %+v", result)
+	}
+	if result := <-denied; !errors.Is(result.Err, sa.ErrUnauthorized) {
+		t.Errorf("expected a 401 to end the watch, got %+v", result)
+	}
+
+	if err := watcher.WatchFunc(nil, "nil-ctx", time.Second, func(PushResult) {}); err == nil {
+		t.Error("expected a nil context to be refused")
+	}
+	if err := watcher.WatchFunc(ctx, "nil-fn", time.Second, nil); err == nil {
+		t.Error("expected a nil callback to be refused")
+	}
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+	validators "github.com/secureauthcorp/saidp-sdk-go/utilities/validators"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Service :
+//	Stateless client for the auth endpoint. Every call builds its own Request, so a Service can be shared
+//	across goroutines and no field of one call leaks into the next.
+type Service struct {
+	client *sa.Client
+}
+
+// Option :
+//	Per call option applied to the Request built by a Service.
+type Option func(r *Request)
+
+// NewService :
+//	Helper function to create an auth Service.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Service: the service.
+func NewService(c *sa.Client) *Service {
+	return &Service{client: c}
+}
+
+// WithNumberEval :
+//	Option to perform number profile evaluation. Only applicable for call and sms auth request types.
+func WithNumberEval() Option {
+	return func(r *Request) {
+		r.EvaluateNum = true
+	}
+}
+
+// WithPushDetails :
+//	Option to send the details of the auth attempt with a push to accept request.
+// Parameters:
+//	companyName: Displayed on the push to accept request.
+//	appDesc: Displayed on the push to accept request.
+//	userIP: Displayed on the push to accept request.
+func WithPushDetails(companyName string, appDesc string, userIP string) Option {
+	return func(r *Request) {
+		r.PushDetails = &PushAcceptDetails{CompanyName: companyName, AppDesc: appDesc, EnduserIP: userIP}
+	}
+}
+
+// Post :
+//	Executes a post to the auth endpoint with a copy of r.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] r: should have all required fields of the struct populated before using.
+//	opts: options applied to the copy before it is sent.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) Post(ctx context.Context, r Request, opts ...Option) (*Response, error) {
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r.PostContext(ctx, s.client)
+}
+
+// PushStatus :
+//	Executes a get request for checking push to accept status.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] refID: the reference id returned by the auth end point when the type is push_accept.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) PushStatus(ctx context.Context, refID string) (*Response, error) {
+	return sa.Execute[Response](ctx, s.client, http.MethodGet, buildEndpointPath(refID), nil)
+}
+
+// ValidateUser :
+//	Validates that the user exists.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidateUser(ctx context.Context, userID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeUserID)})
+}
+
+// ValidatePassword :
+//	Validates the password of a user.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+//	[Required] password: the password of the user you wish to validate.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidatePassword(ctx context.Context, userID string, password string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypePassword), Token: password})
+}
+
+// ValidateKba :
+//	Validates the answer to a knowledge based question.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+//	[Required] answer: the answer for the kbq value you want to validate.
+//	[Required] kbqID: the id of the kbq the answer will be validated against.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidateKba(ctx context.Context, userID string, answer string, kbqID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeKba), Token: answer, FactorID: kbqID})
+}
+
+// ValidateOath :
+//	Validates an oath otp.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+//	[Required] oathOTP: the otp value to be validated.
+//	[Required] deviceID: from factor_id of the user endpoint, the device identifier to which Oath is registered to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidateOath(ctx context.Context, userID string, oathOTP string, deviceID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeOath), Token: oathOTP, FactorID: deviceID})
+}
+
+// ValidatePin :
+//	Validates the pin of a user.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+//	[Required] pin: the pin to be validated.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidatePin(ctx context.Context, userID string, pin string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypePin), Token: pin})
+}
+
+// SendCall :
+//	Sends an otp via phone call.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user the call will be sent to.
+//	[Required] factorID: from factor_id of a user endpoint call. Identifier of the profile
+//		   attribute that the call should be sent to.
+//	opts: e.g. WithNumberEval().
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendCall(ctx context.Context, userID string, factorID string, opts ...Option) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeCall), FactorID: factorID}, opts...)
+}
+
+// SendSMS :
+//	Sends an otp via sms.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user the sms will be sent to.
+//	[Required] factorID: from factor_id of a user endpoint sms. Identifier of the profile
+//		   attribute that the sms should be sent to.
+//	opts: e.g. WithNumberEval().
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendSMS(ctx context.Context, userID string, factorID string, opts ...Option) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeSMS), FactorID: factorID}, opts...)
+}
+
+// SendEmail :
+//	Sends an otp via email.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user the email will be sent to.
+//	[Required] factorID: from factor_id of a user endpoint email. Identifier of the profile
+//		   attribute that the email should be sent to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendEmail(ctx context.Context, userID string, factorID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeEmail), FactorID: factorID})
+}
+
+// SendAdHoc :
+//	Sends an otp to a phone number or email address not in the backing datastore.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user you wish to validate.
+//	[Required] token: the number or email address the OTP will be delivered to.
+//	[Required] reqType: the type of delivery method to be used. Only TypeCall, TypeSMS or TypeEmail are valid.
+//	opts: e.g. WithNumberEval(), only valid for call and sms.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendAdHoc(ctx context.Context, userID string, token string, reqType AuthType, opts ...Option) (*Response, error) {
+	if !validators.ValidateRequestType(string(reqType)) {
+		return nil, errors.New("Not a valid type, valid types: call, sms, or email")
+	}
+	r := Request{UserID: userID, ReqType: string(reqType), Token: token}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	if r.EvaluateNum && reqType == TypeEmail {
+		return nil, errors.New("Number evaluation can only be used with call or sms reqTypes")
+	}
+	return r.PostContext(ctx, s.client)
+}
+
+// SendPush :
+//	Sends an otp via push notification.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user the push notification will be sent to.
+//	[Required] deviceID: from factor_id of the user endpoint, the device identifier which is registered.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendPush(ctx context.Context, userID string, deviceID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypePush), FactorID: deviceID})
+}
+
+// SendPushAccept :
+//	Sends a push to accept request. The answer is followed with PushStatus or a PushWatcher.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user the push to accept will be sent to.
+//	[Required] deviceID: from factor_id of the user endpoint, the device identifier which is registered.
+//	opts: e.g. WithPushDetails(companyName, appDesc, userIP).
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendPushAccept(ctx context.Context, userID string, deviceID string, opts ...Option) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypePushAccept), FactorID: deviceID}, opts...)
+}
+
+// SendHelpDesk :
+//	Sends an otp to a help_desk agent.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the userID of the user requesting the help_desk method.
+//	[Required] factorID: from factor_id of the user endpoint, the help_desk option.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SendHelpDesk(ctx context.Context, userID string, factorID string) (*Response, error) {
+	return s.Post(ctx, Request{UserID: userID, ReqType: string(TypeHelpDesk), FactorID: factorID})
+}
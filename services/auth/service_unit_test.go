@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/secureauthcorp/saidp-sdk-go/saidptest"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestService_Unit(t *testing.T) {
+	ctx := context.Background()
+	server := saidptest.NewServer()
+	defer server.Close()
+	server.AddUser(saidptest.User{ID: uUser, Factors: []saidptest.Factor{
+		{Type: "phone", ID: "Phone1", Value: "xxx-xxx-1234", Capabilities: []string{"sms", "call"}},
+		{Type: "push", ID: "device1", Value: "iPhone", Capabilities: []string{"push_accept"}},
+	}})
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluated := func(requests []saidptest.Request) int {
+		n := 0
+		for _, r := range requests {
+			if bytes.Contains(r.Body, []byte(`"evaluate_number":true`)) {
+				n++
+			}
+		}
+		return n
+	}
+
+	svc := NewService(client)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(eval bool) {
+			defer wg.Done()
+			var opts []Option
+			if eval {
+				opts = append(opts, WithNumberEval())
+			}
+			if resp, err := svc.SendSMS(ctx, uUser, "Phone1", opts...); err != nil || !resp.IsValid() {
+				t.Errorf("unexpected sms response %v %v", resp, err)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	if n := evaluated(server.Requests()); n != 5 {
+		t.Errorf("expected 5 evaluated requests, got %d", n)
+	}
+
+	r := new(Request)
+	if _, err := r.SendSMSOtpWithEval(client, uUser, "Phone1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SendSMSOtp(client, uUser, "Phone1"); err != nil {
+		t.Fatal(err)
+	}
+	if *r != (Request{}) {
+		t.Errorf("expected the receiver to be left untouched, got %+v", r)
+	}
+	if n := evaluated(server.Requests()); n != 6 {
+		t.Errorf("expected number evaluation not to leak into the next request, got %d evaluated requests", n)
+	}
+
+	if _, err := svc.SendPushAccept(ctx, uUser, "device1", WithPushDetails("Acme", "Portal", uUserIP)); err != nil {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if body := requests[len(requests)-1].Body; !bytes.Contains(body, []byte(`"company_name":"Acme"`)) {
+		t.Errorf("expected the push details to be sent, got %s", body)
+	}
+	if _, err := svc.SendAdHoc(ctx, uUser, "user@example.com", TypeEmail, WithNumberEval()); err == nil {
+		t.Error("expected number evaluation to be rejected for email")
+	}
+	if _, err := svc.SendAdHoc(ctx, uUser, "user@example.com", TypePush); err == nil {
+		t.Error("expected an ad-hoc push to be rejected")
+	}
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// AuthType : type of an auth endpoint request, sent as "type".
+type AuthType string
+
+const (
+	TypeUserID     AuthType = "user_id"
+	TypePassword   AuthType = "password"
+	TypeKba        AuthType = "kba"
+	TypeOath       AuthType = "oath"
+	TypePin        AuthType = "pin"
+	TypeCall       AuthType = "call"
+	TypeSMS        AuthType = "sms"
+	TypeEmail      AuthType = "email"
+	TypePush       AuthType = "push"
+	TypePushAccept AuthType = "push_accept"
+	TypeHelpDesk   AuthType = "help_desk"
+)
+
+// Status : status of an auth endpoint response.
+type Status string
+
+const (
+	StatusFound       Status = "found"
+	StatusNotFound    Status = "not_found"
+	StatusValid       Status = "valid"
+	StatusInvalid     Status = "invalid"
+	StatusFailed      Status = "failed"
+	StatusServerError Status = "server_error"
+)
+
+// PushState : state of a push to accept request, returned as the message of a status check.
+type PushState string
+
+const (
+	PushPending  PushState = "PENDING"
+	PushAccepted PushState = "ACCEPTED"
+	PushDenied   PushState = "DENIED"
+	PushFailed   PushState = "FAILED"
+	PushExpired  PushState = "EXPIRED"
+)
+
+// ErrUnknownAuthType : returned before any network call when a Request has an unknown ReqType.
+var ErrUnknownAuthType = errors.New("auth: unknown auth type")
+
+// IsTerminal :
+//	Function reporting whether the user answered or the request ended, i.e. any known state but PENDING.
+// Returns:
+//	bool: true for ACCEPTED, DENIED, FAILED and EXPIRED.
+func (s PushState) IsTerminal() bool {
+	switch s {
+	case PushAccepted, PushDenied, PushFailed, PushExpired:
+		return true
+	}
+	return false
+}
+
+// Type :
+//	Helper function returning ReqType as an AuthType.
+// Returns:
+//	AuthType: the type of the request.
+func (r *Request) Type() AuthType {
+	return AuthType(r.ReqType)
+}
+
+// StatusValue :
+//	Helper function returning Status as a Status.
+// Returns:
+//	Status: the status of the response.
+func (r *Response) StatusValue() Status {
+	return Status(r.Status)
+}
+
+// IsValid :
+//	Helper function reporting whether a validation or delivery request succeeded.
+// Returns:
+//	bool: true when Status is valid.
+func (r *Response) IsValid() bool {
+	return r.StatusValue() == StatusValid
+}
+
+// IsFound :
+//	Helper function reporting whether the user or reference id exists.
+// Returns:
+//	bool: true when Status is found.
+func (r *Response) IsFound() bool {
+	return r.StatusValue() == StatusFound
+}
+
+// PushState :
+//	Helper function returning the state of a push to accept request from a status check response.
+// Returns:
+//	PushState: the state carried by Message.
+//	Error: ErrUnknownPushStatus when Message is not a known state.
+func (r *Response) PushState() (PushState, error) {
+	state := PushState(r.Message)
+	if state != PushPending && !state.IsTerminal() {
+		return state, fmt.Errorf("%w: %q", ErrUnknownPushStatus, r.Message)
+	}
+	return state, nil
+}
@@ -1,13 +1,7 @@
 package behavebio
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -104,27 +98,21 @@ type Results struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Get(c *sa.Client, endpoint string) (*Response, error) {
-	httpRequest, err := c.BuildGetRequest(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	behaveResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, behaveResponse); err != nil {
-		return nil, err
-	}
-	behaveResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	behaveResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return behaveResponse, nil
+	return r.GetContext(context.Background(), c, endpoint)
+}
+
+// GetContext :
+//	Executes a get to the behaviobio javascript endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: request struct to make get easy. should be empty for the use in get operations
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint for the get request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) GetContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodGet, endpoint, nil)
 }
 
 // Post :
@@ -137,31 +125,21 @@ func (r *Request) Get(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	behaveResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, behaveResponse); err != nil {
-		return nil, err
-	}
-	behaveResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	behaveResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return behaveResponse, nil
+	return r.PostContext(context.Background(), c, endpoint)
+}
+
+// PostContext :
+//	Executes a post to the behavioBio endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: should have all the required fields for the post type.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint for the post request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // Put :
@@ -174,31 +152,21 @@ func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Put(c *sa.Client, endpoint string) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPutRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	behaveResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, behaveResponse); err != nil {
-		return nil, err
-	}
-	behaveResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	behaveResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return behaveResponse, nil
+	return r.PutContext(context.Background(), c, endpoint)
+}
+
+// PutContext :
+//	Executes a put to the behavioBio endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: should have all the required fields for the put type.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint for the put request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PutContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPut, endpoint, r)
 }
 
 // GetBehaveJs :
@@ -209,11 +177,7 @@ func (r *Request) Put(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) GetBehaveJs(c *sa.Client) (*Response, error) {
-	behaveResponse, err := r.Get(c, jsEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return behaveResponse, nil
+	return NewService(c).GetBehaveJs(context.Background())
 }
 
 // PostBehaveProfile :
@@ -228,15 +192,7 @@ func (r *Request) GetBehaveJs(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) PostBehaveProfile(c *sa.Client, userID string, behaveProfile string, hostAddress string, userAgent string) (*Response, error) {
-	r.UserID = userID
-	r.BehaviorProfile = behaveProfile
-	r.HostAddress = hostAddress
-	r.UserAgent = userAgent
-	behaveResponse, err := r.Post(c, behaveEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return behaveResponse, nil
+	return NewService(c).PostBehaveProfile(context.Background(), userID, behaveProfile, hostAddress, userAgent)
 }
 
 // ResetBehaveProfile :
@@ -252,15 +208,14 @@ func (r *Request) PostBehaveProfile(c *sa.Client, userID string, behaveProfile s
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ResetBehaveProfile(c *sa.Client, userID string, fieldName string, fieldType string, deviceType string) (*Response, error) {
-	r.UserID = userID
-	r.FieldName = fieldName
-	r.FieldType = fieldType
-	r.DeviceType = deviceType
-	behaveResponse, err := r.Put(c, behaveEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return behaveResponse, nil
+	return NewService(c).ResetBehaveProfile(context.Background(), userID, fieldName, fieldType, deviceType)
+}
+
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
 }
 
 //IsSignatureValid :
@@ -270,24 +225,7 @@ func (r *Request) ResetBehaveProfile(c *sa.Client, userID string, fieldName stri
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -45,7 +45,7 @@ import (
 
 const (
 	uAppID         = "12345"
-	uAppKey        = "12345"
+	uAppKey        = "123456"
 	uHost          = "idp.host.com"
 	uRealm         = "secureauth1"
 	uPort          = 443
@@ -59,6 +59,7 @@ func TestBehaveBio_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	behaveJSTest, err := behaveBioJS(client)
 	if err != nil {
@@ -0,0 +1,130 @@
+package behavebio
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// sentRequest : method, path and body of a request received by the recording server.
+type sentRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// newRecordingClient :
+//	Helper starting a server that records every request and answers with a valid response, and returning a client
+//	of that server and a function returning the requests recorded since its last call.
+func newRecordingClient(t *testing.T) (*sa.Client, func() []sentRequest) {
+	var mu sync.Mutex
+	var sent []sentRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		sent = append(sent, sentRequest{Method: r.Method, Path: r.URL.EscapedPath(), Body: string(body)})
+		mu.Unlock()
+		w.Write([]byte(`{"status":"valid","message":""}`))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	client, err := sa.NewClient(uAppID, uAppKey, u.Hostname(), port, uRealm, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() []sentRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := sent
+		sent = nil
+		return taken
+	}
+}
+
+// sendBoth :
+//	Helper making the same call through the Service and through the legacy wrapper, checking that both sent the
+//	same request to the expected endpoint, and returning that request.
+func sendBoth(t *testing.T, sent func() []sentRequest, method string, endpoint string, service func() (*Response, error), legacy func() (*Response, error)) sentRequest {
+	t.Helper()
+	if _, err := service(); err != nil {
+		t.Fatalf("%s %s: %v", method, endpoint, err)
+	}
+	if _, err := legacy(); err != nil {
+		t.Fatalf("%s %s: %v", method, endpoint, err)
+	}
+	requests := sent()
+	if len(requests) != 2 {
+		t.Fatalf("%s %s: expected one request from the service and one from the legacy wrapper, got %+v", method, endpoint, requests)
+	}
+	if requests[0] != requests[1] {
+		t.Errorf("%s %s: expected the service and the legacy wrapper to send the same request, got %+v and %+v", method, endpoint, requests[0], requests[1])
+	}
+	if requests[0].Method != method || requests[0].Path != "/"+uRealm+endpoint {
+		t.Errorf("expected %s /%s%s, got %s %s", method, uRealm, endpoint, requests[0].Method, requests[0].Path)
+	}
+	return requests[0]
+}
+
+func TestService_Unit(t *testing.T) {
+	ctx := context.Background()
+	client, sent := newRecordingClient(t)
+	svc := NewService(client)
+	r := new(Request)
+
+	sendBoth(t, sent, http.MethodGet, "/api/v1/behavebio/js",
+		func() (*Response, error) { return svc.GetBehaveJs(ctx) },
+		func() (*Response, error) { return r.GetBehaveJs(client) })
+	sendBoth(t, sent, http.MethodPost, "/api/v1/behavebio",
+		func() (*Response, error) {
+			return svc.PostBehaveProfile(ctx, uUser, uBehaveProfile, "192.168.0.1", uUserAgent)
+		},
+		func() (*Response, error) {
+			return r.PostBehaveProfile(client, uUser, uBehaveProfile, "192.168.0.1", uUserAgent)
+		})
+	// Resetting with the same service and request must not send the profile posted before.
+	reset := sendBoth(t, sent, http.MethodPut, "/api/v1/behavebio",
+		func() (*Response, error) { return svc.ResetBehaveProfile(ctx, uUser, "ALL", "ALL", "ALL") },
+		func() (*Response, error) { return r.ResetBehaveProfile(client, uUser, "ALL", "ALL", "ALL") })
+	if strings.Contains(reset.Body, "behaviorProfile") || strings.Contains(reset.Body, "userAgent") {
+		t.Errorf("expected the posted profile not to leak into the reset request, got %s", reset.Body)
+	}
+	if *r != (Request{}) {
+		t.Errorf("expected the receiver to be left untouched, got %+v", r)
+	}
+}
@@ -2,12 +2,7 @@ package changepassword
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -73,32 +68,22 @@ type Request struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client, userID string) (*Response, error) {
+	return r.PostContext(context.Background(), c, userID)
+}
+
+// PostContext :
+//	Executes a post to the users endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to perform the post for.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client, userID string) (*Response, error) {
 	endpoint := buildEndpointPath(userID)
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	changeResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, changeResponse); err != nil {
-		return nil, err
-	}
-	changeResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	changeResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return changeResponse, nil
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // ChangePassword :
@@ -113,13 +98,7 @@ func (r *Request) Post(c *sa.Client, userID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ChangePassword(c *sa.Client, userID string, currentPwd string, newPwd string) (*Response, error) {
-	r.CurrentPwd = currentPwd
-	r.NewPwd = newPwd
-	changeResponse, err := r.Post(c, userID)
-	if err != nil {
-		return nil, err
-	}
-	return changeResponse, nil
+	return NewService(c).ChangePassword(context.Background(), userID, currentPwd, newPwd)
 }
 
 // buildEndpointPath:
@@ -132,6 +111,13 @@ func buildEndpointPath(userID string) string {
 	return buffer.String()
 }
 
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
 //IsSignatureValid :
 //	Helper function to validate the SecureAuth Response signature in X-SA-SIGNATURE
 // Parameters:
@@ -139,24 +125,7 @@ func buildEndpointPath(userID string) string {
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -0,0 +1,66 @@
+package changepassword
+
+import (
+	"context"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Service :
+//	Stateless client for the change password endpoint. Every call builds its own Request, so a Service can be shared
+//	across goroutines.
+type Service struct {
+	client *sa.Client
+}
+
+// NewService :
+//	Helper function to create a changepassword Service.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Service: the service.
+func NewService(c *sa.Client) *Service {
+	return &Service{client: c}
+}
+
+// ChangePassword :
+//	Changes the password of a user.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the username of the user.
+//	[Required] currentPwd: the user's current password.
+//	[Required] newPwd: the password the user wishes to change their password to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ChangePassword(ctx context.Context, userID string, currentPwd string, newPwd string) (*Response, error) {
+	r := Request{CurrentPwd: currentPwd, NewPwd: newPwd}
+	return r.PostContext(ctx, s.client, userID)
+}
@@ -1,13 +1,7 @@
 package dfp
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -88,27 +82,21 @@ type Request struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Get(c *sa.Client, endpoint string) (*Response, error) {
-	httpRequest, err := c.BuildGetRequest(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	dfpResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, dfpResponse); err != nil {
-		return nil, err
-	}
-	dfpResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	dfpResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return dfpResponse, nil
+	return r.GetContext(context.Background(), c, endpoint)
+}
+
+// GetContext :
+//	Executes a get to the dfp/js endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: struct used to perform get request.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	endpoint: the endpoint for the get request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) GetContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodGet, endpoint, nil)
 }
 
 // Post :
@@ -121,31 +109,21 @@ func (r *Request) Get(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	dfpResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, dfpResponse); err != nil {
-		return nil, err
-	}
-	dfpResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	dfpResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return dfpResponse, nil
+	return r.PostContext(context.Background(), c, endpoint)
+}
+
+// PostContext :
+//	Executes a post to the dfp endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint for the post request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // GetDfpJs :
@@ -156,11 +134,7 @@ func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) GetDfpJs(c *sa.Client) (*Response, error) {
-	dpfResponse, err := r.Get(c, jsEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return dpfResponse, nil
+	return NewService(c).GetDfpJs(context.Background())
 }
 
 // ValidateDfp :
@@ -175,19 +149,7 @@ func (r *Request) GetDfpJs(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ValidateDfp(c *sa.Client, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
-	var m map[string]interface{}
-	if err := json.Unmarshal([]byte(fingerprint), &m); err != nil {
-		return nil, err
-	}
-	r.Fingerprint = m
-	r.FingerprintID = fingerprintID
-	r.UserID = userID
-	r.HostAddress = hostAddress
-	validateResponse, err := r.Post(c, valEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return validateResponse, nil
+	return NewService(c).ValidateDfp(context.Background(), userID, hostAddress, fingerprintID, fingerprint)
 }
 
 // ConfirmDfp :
@@ -200,13 +162,7 @@ func (r *Request) ValidateDfp(c *sa.Client, userID string, hostAddress string, f
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ConfirmDfp(c *sa.Client, userID string, fingerprintID string) (*Response, error) {
-	r.UserID = userID
-	r.FingerprintID = fingerprintID
-	confirmResponse, err := r.Post(c, confirmEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return confirmResponse, nil
+	return NewService(c).ConfirmDfp(context.Background(), userID, fingerprintID)
 }
 
 // ScoreDfp :
@@ -221,19 +177,7 @@ func (r *Request) ConfirmDfp(c *sa.Client, userID string, fingerprintID string)
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) ScoreDfp(c *sa.Client, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
-	var m map[string]interface{}
-	if err := json.Unmarshal([]byte(fingerprint), &m); err != nil {
-		return nil, err
-	}
-	r.Fingerprint = m
-	r.FingerprintID = fingerprintID
-	r.UserID = userID
-	r.HostAddress = hostAddress
-	validateResponse, err := r.Post(c, scoreEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return validateResponse, nil
+	return NewService(c).ScoreDfp(context.Background(), userID, hostAddress, fingerprintID, fingerprint)
 }
 
 // SaveDfp :
@@ -248,19 +192,14 @@ func (r *Request) ScoreDfp(c *sa.Client, userID string, hostAddress string, fing
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) SaveDfp(c *sa.Client, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
-	var m map[string]interface{}
-	if err := json.Unmarshal([]byte(fingerprint), &m); err != nil {
-		return nil, err
-	}
-	r.Fingerprint = m
-	r.FingerprintID = fingerprintID
-	r.UserID = userID
-	r.HostAddress = hostAddress
-	validateResponse, err := r.Post(c, saveEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	return validateResponse, nil
+	return NewService(c).SaveDfp(context.Background(), userID, hostAddress, fingerprintID, fingerprint)
+}
+
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
 }
 
 //IsSignatureValid :
@@ -270,24 +209,7 @@ func (r *Request) SaveDfp(c *sa.Client, userID string, hostAddress string, finge
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
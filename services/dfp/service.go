@@ -0,0 +1,133 @@
+package dfp
+
+import (
+	"context"
+	"encoding/json"
+
+	sa "github.com/secureauthcorp/saidp-sdk-go"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// Service :
+//	Stateless client for the dfp endpoints. Every call builds its own Request, so a Service can be shared
+//	across goroutines.
+type Service struct {
+	client *sa.Client
+}
+
+// NewService :
+//	Helper function to create a dfp Service.
+// Parameters:
+//	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Service: the service.
+func NewService(c *sa.Client) *Service {
+	return &Service{client: c}
+}
+
+// GetDfpJs :
+//	Retrieves the fingerprint javascript source.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) GetDfpJs(ctx context.Context) (*Response, error) {
+	return new(Request).GetContext(ctx, s.client, jsEndpoint)
+}
+
+// ValidateDfp :
+//	Validates a device fingerprint.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the username of the user you wish to validate a dfp for.
+//	[Required] hostAddress: the ip address of the user's device.
+//	fingerprintID: if it is a known fingerprint, provide the fingerprint id to validate against.
+//	[Required] fingerprint: the json string returned by the javascript dfp script.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ValidateDfp(ctx context.Context, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
+	return s.postFingerprint(ctx, valEndpoint, userID, hostAddress, fingerprintID, fingerprint)
+}
+
+// ConfirmDfp :
+//	Confirms a device fingerprint.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the username of the user you wish to confirm a dfp for.
+//	[Required] fingerprintID: the fingerprint id of the fingerprint you wish to confirm.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ConfirmDfp(ctx context.Context, userID string, fingerprintID string) (*Response, error) {
+	r := Request{UserID: userID, FingerprintID: fingerprintID}
+	return r.PostContext(ctx, s.client, confirmEndpoint)
+}
+
+// ScoreDfp :
+//	Scores a device fingerprint.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the username of the user you wish to score a dfp for.
+//	[Required] hostAddress: the ip address of the user's device.
+//	fingerprintID: if it is a known fingerprint, provide the fingerprint id to score against.
+//	[Required] fingerprint: the json string returned by the javascript dfp script.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) ScoreDfp(ctx context.Context, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
+	return s.postFingerprint(ctx, scoreEndpoint, userID, hostAddress, fingerprintID, fingerprint)
+}
+
+// SaveDfp :
+//	Saves a device fingerprint.
+// Parameters:
+//	[Required] ctx: context controlling the lifetime of the request.
+//	[Required] userID: the username of the user you wish to save a dfp for.
+//	[Required] hostAddress: the ip address of the user's device.
+//	fingerprintID: if it is a known fingerprint, provide the fingerprint id to update.
+//	[Required] fingerprint: the json string returned by the javascript dfp script.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (s *Service) SaveDfp(ctx context.Context, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
+	return s.postFingerprint(ctx, saveEndpoint, userID, hostAddress, fingerprintID, fingerprint)
+}
+
+// postFingerprint :
+//	non-exportable helper posting a fingerprint json string to one of the dfp endpoints.
+func (s *Service) postFingerprint(ctx context.Context, endpoint string, userID string, hostAddress string, fingerprintID string, fingerprint string) (*Response, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(fingerprint), &m); err != nil {
+		return nil, err
+	}
+	r := Request{UserID: userID, HostAddress: hostAddress, FingerprintID: fingerprintID, Fingerprint: m}
+	return r.PostContext(ctx, s.client, endpoint)
+}
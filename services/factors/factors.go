@@ -2,13 +2,10 @@ package factors
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
 )
@@ -76,28 +73,55 @@ type Request struct{}
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Get(c *sa.Client, user string) (*Response, error) {
+	return r.GetContext(context.Background(), c, user)
+}
+
+// GetContext :
+//	Executes a get to the users endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: empty struct used to make Get easy.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] user: the user you want to get factor information for.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) GetContext(ctx context.Context, c *sa.Client, user string) (*Response, error) {
 	endpoint := buildEndpointPath(user)
-	httpRequest, err := c.BuildGetRequest(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	factorResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, factorResponse); err != nil {
-		return nil, err
+	return sa.Execute[Response](ctx, c, http.MethodGet, endpoint, nil)
+}
+
+// Delete :
+//	Executes a delete to the users endpoint, unregistering a device or factor from the user.
+// Parameters:
+//	[Required] r: empty struct used to make Delete easy.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] user: the user the factor belongs to.
+//	[Required] factorID: the id of the factor (device id) to unregister.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) Delete(c *sa.Client, user string, factorID string) (*Response, error) {
+	return r.DeleteContext(context.Background(), c, user, factorID)
+}
+
+// DeleteContext :
+//	Executes a delete to the users endpoint, unregistering a device or factor from the user, bound to ctx.
+// Parameters:
+//	[Required] r: empty struct used to make Delete easy.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] user: the user the factor belongs to.
+//	[Required] factorID: the id of the factor (device id) to unregister.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) DeleteContext(ctx context.Context, c *sa.Client, user string, factorID string) (*Response, error) {
+	if len(factorID) <= 0 {
+		return nil, errors.New("A factor id is required")
 	}
-	factorResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	factorResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return factorResponse, nil
+	endpoint := buildFactorEndpointPath(user, factorID)
+	return sa.Execute[Response](ctx, c, http.MethodDelete, endpoint, nil)
 }
 
 // buildEndpointPath:
@@ -110,6 +134,23 @@ func buildEndpointPath(user string) string {
 	return buffer.String()
 }
 
+// buildFactorEndpointPath:
+//	non-exportable helper to build the endpoint api path with username and factor id injected.
+func buildFactorEndpointPath(user string, factorID string) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(buildEndpointPath(user))
+	buffer.WriteString("/")
+	buffer.WriteString(url.PathEscape(factorID))
+	return buffer.String()
+}
+
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
 //IsSignatureValid :
 //	Helper function to validate the SecureAuth Response signature in X-SA-SIGNATURE
 // Parameters:
@@ -117,24 +158,7 @@ func buildEndpointPath(user string) string {
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -44,7 +44,7 @@ import (
 
 const (
 	uAppID  = "12345"
-	uAppKey = "12345"
+	uAppKey = "123456"
 	uHost   = "idp.host.com"
 	uRealm  = "secureauth1"
 	uPort   = 443
@@ -58,6 +58,7 @@ func TestFactors_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	n := time.Now()
 	headers := map[string]string{
@@ -85,6 +86,40 @@ func TestFactors_Unit(t *testing.T) {
 	}
 }
 
+func TestFactorsDelete_Unit(t *testing.T) {
+	defer gock.Off()
+
+	client, err := sa.NewClient(uAppID, uAppKey, uHost, uPort, uRealm, true, false)
+	if err != nil {
+		t.Error(err)
+	}
+	gock.InterceptClient(client.HTTPClient())
+
+	deleteJSON := `{"status":"success","message":"","user_id":"user"}`
+	n := time.Now()
+	headers := map[string]string{
+		"X-SA-DATE":      n.String(),
+		"X-SA-SIGNATURE": makeResponseSignature(client, deleteJSON, n.String()),
+	}
+	gock.New("https://idp.host.com:443").
+		Delete("/secureauth1/api/v1/users/" + uUser + "/factors/8117b62897734d71b48ecdcab19bd437").
+		Reply(200).
+		BodyString(deleteJSON).
+		SetHeaders(headers)
+
+	factorRequest := new(Request)
+	factorResponse, err := factorRequest.Delete(client, uUser, "8117b62897734d71b48ecdcab19bd437")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factorResponse.Status != "success" {
+		t.Errorf("unexpected delete response: %s", factorResponse.RawJSON)
+	}
+	if _, err := factorRequest.Delete(client, uUser, ""); err == nil {
+		t.Error("expected an error for an empty factor id")
+	}
+}
+
 func generateResponse() string {
 	var jsonFactors = `{"status":"found","message":"","user_id":"jsmith","factors":[{"type":"phone","id":"Phone1","value":"123-456-7890","capabilities":["call"]},{"type":"phone","id":"Phone2","value":"987-654-3210","capabilities":["sms","call"]},{"type":"email","id":"Email1","value":"jsmith@company.com"},{"type":"kbq","id":"KBQ1","value":"What city were you born in?"},{"type":"kbq","id":"KBQ2","value":"What was your favorite childhood game?"},{"type":"kbq","id":"KBQ3","value":"What was your dream job as a child?"},{"type":"kbq","id":"KBQ4","value":"Who is your personal hero?"},{"type":"kbq","id":"KBQ5","value":"What is the last name of your favorite school teacher?"},{"type":"kbq","id":"KBQ6","value":"What is the name of your favorite childhood pet?"},{"type":"help_desk","id":"HelpDesk1","value":"987-654-3210"},{"type":"help_desk","id":"HelpDesk2","value":"987-654-3211"},{"type":"push","id":"8117b62897734d71b48ecdcab19bd437","value":"HTC One","capabilities":["push","push_accept"]},{"type":"oath","id":"63c6b390cac04efb8d283828ed29c120","value":"SecureAuth OTP Mobile App"},{"type":"pin","value":"Private PIN"}]}`
 	response := new(Response)
@@ -2,13 +2,7 @@ package groups
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
 	"net/http"
 	"net/url"
 
@@ -77,31 +71,48 @@ type Request struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	groupsResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, groupsResponse); err != nil {
-		return nil, err
-	}
-	groupsResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	groupsResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return groupsResponse, nil
+	return r.PostContext(context.Background(), c, endpoint)
+}
+
+// PostContext :
+//	Executes a post to the users or groups endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint perform the post to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
+}
+
+// Delete :
+//	Executes a delete to the users or groups endpoint.
+// Parameters:
+// 	[Required] r: should be empty for this call.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint perform the delete to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) Delete(c *sa.Client, endpoint string) (*Response, error) {
+	return r.DeleteContext(context.Background(), c, endpoint)
+}
+
+// DeleteContext :
+//	Executes a delete to the users or groups endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should be empty for this call.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint perform the delete to.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) DeleteContext(ctx context.Context, c *sa.Client, endpoint string) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodDelete, endpoint, nil)
 }
 
 // AddUserToGroup :
@@ -115,12 +126,7 @@ func (r *Request) Post(c *sa.Client, endpoint string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.\
 func (r *Request) AddUserToGroup(c *sa.Client, userID string, groupID string) (*Response, error) {
-	endpoint := buildSingleUserToSingleGroupEndpoint(userID, groupID)
-	groupsResponse, err := r.Post(c, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return groupsResponse, nil
+	return NewService(c).AddUserToGroup(context.Background(), userID, groupID)
 }
 
 // AddUserToGroups :
@@ -134,13 +140,7 @@ func (r *Request) AddUserToGroup(c *sa.Client, userID string, groupID string) (*
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) AddUserToGroups(c *sa.Client, userID string, groups []string) (*Response, error) {
-	r.GroupNames = groups
-	endpoint := buildSingleUserToMultiGroupEndpoint(userID)
-	groupsResponse, err := r.Post(c, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return groupsResponse, nil
+	return NewService(c).AddUserToGroups(context.Background(), userID, groups)
 }
 
 // AddGroupToUser :
@@ -154,12 +154,7 @@ func (r *Request) AddUserToGroups(c *sa.Client, userID string, groups []string)
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) AddGroupToUser(c *sa.Client, groupID string, userID string) (*Response, error) {
-	endpoint := buildSingleGroupToSingleUserEndpoint(groupID, userID)
-	groupsResponse, err := r.Post(c, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return groupsResponse, nil
+	return NewService(c).AddGroupToUser(context.Background(), groupID, userID)
 }
 
 // AddGroupToUsers :
@@ -173,13 +168,35 @@ func (r *Request) AddGroupToUser(c *sa.Client, groupID string, userID string) (*
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) AddGroupToUsers(c *sa.Client, groupID string, users []string) (*Response, error) {
-	r.UserIds = users
-	endpoint := buildSingleGroupToMultiUsersEndpoint(groupID)
-	groupsResponse, err := r.Post(c, endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return groupsResponse, nil
+	return NewService(c).AddGroupToUsers(context.Background(), groupID, users)
+}
+
+// RemoveUserFromGroup :
+//	Helper function for making user deletes to remove a single user from a single group.
+// Parameters:
+//	[Required] r: should be empty for this call.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to remove from a group.
+//	[Required] groupID: the name of the group to remove the user from.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) RemoveUserFromGroup(c *sa.Client, userID string, groupID string) (*Response, error) {
+	return NewService(c).RemoveUserFromGroup(context.Background(), userID, groupID)
+}
+
+// RemoveGroupFromUser :
+//	Helper function for making group deletes to remove a single group from a single user.
+// Parameters:
+//	[Required] r: should be empty for this call.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] groupID: the name of the group to remove the user from.
+//	[Required] userID: the username of the user to remove from the group.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) RemoveGroupFromUser(c *sa.Client, groupID string, userID string) (*Response, error) {
+	return NewService(c).RemoveGroupFromUser(context.Background(), groupID, userID)
 }
 
 // buildSingleUserToSingleGroupEndpoint :
@@ -192,7 +209,6 @@ func buildSingleUserToSingleGroupEndpoint(userID string, groupID string) string
 	u := &url.URL{Path: groupID}
 	escapedGroup := u.String()
 	buffer.WriteString(escapedGroup)
-	fmt.Println(buffer.String())
 	return buffer.String()
 }
 
@@ -231,6 +247,13 @@ func buildSingleUserToMultiGroupEndpoint(userID string) string {
 	return buffer.String()
 }
 
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
 //IsSignatureValid :
 //	Helper function to validate the SecureAuth Response signature in X-SA-SIGNATURE
 // Parameters:
@@ -238,24 +261,7 @@ func buildSingleUserToMultiGroupEndpoint(userID string) string {
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -45,7 +45,7 @@ import (
 
 const (
 	uAppID       = "12345"
-	uAppKey      = "12345"
+	uAppKey      = "123456"
 	uHost        = "idp.host.com"
 	uRealm       = "secureauth1"
 	uPort        = 443
@@ -65,6 +65,7 @@ func TestGroup_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 	userToGroupTest, err := userToGroup(client)
 	if err != nil {
 		t.Error(err)
@@ -96,6 +97,14 @@ func TestGroup_Unit(t *testing.T) {
 	if !groupToUsersTest {
 		t.Error("Add group to users test failed")
 	}
+
+	userFromGroupTest, err := userFromGroup(client)
+	if err != nil {
+		t.Error(err)
+	}
+	if !userFromGroupTest {
+		t.Error("Remove user from group test failed")
+	}
 }
 
 func userToGroup(client *sa.Client) (bool, error) {
@@ -262,6 +271,44 @@ func groupToUsers(client *sa.Client) (bool, error) {
 	return true, nil
 }
 
+func userFromGroup(client *sa.Client) (bool, error) {
+	defer gock.Off()
+
+	responseMock := &Response{
+		Status:  "success",
+		Message: "",
+	}
+	bytes, err := json.Marshal(responseMock)
+	if err != nil {
+		fmt.Println(err)
+	}
+	responseMockJSON := string(bytes)
+	n := time.Now()
+	headers := map[string]string{
+		"X-SA-DATE":      n.String(),
+		"X-SA-SIGNATURE": makeResponseSignature(client, responseMockJSON, n.String()),
+	}
+	// Set up a test responder for the api.
+	gock.New("https://idp.host.com:443").
+		Delete("/secureauth1/api/v1/users/" + uUser1 + "/groups/" + uGroup1).
+		Reply(200).
+		BodyString(responseMockJSON).
+		SetHeaders(headers)
+	groupRequest := new(Request)
+	groupResponse, err := groupRequest.RemoveUserFromGroup(client, uUser1, uGroup1)
+	if err != nil {
+		return false, err
+	}
+	valid, err := groupResponse.IsSignatureValid(client)
+	if err != nil {
+		return false, err
+	}
+	if !valid {
+		return false, errors.New("Response signature is invalid")
+	}
+	return true, nil
+}
+
 func makeResponseSignature(c *sa.Client, response string, timeStamp string) string {
 	var buffer bytes.Buffer
 	buffer.WriteString(timeStamp)
@@ -1,13 +1,7 @@
 package numberprofile
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
+	"context"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -129,31 +123,20 @@ type CarrierInfo struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	numberProfileResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, numberProfileResponse); err != nil {
-		return nil, err
-	}
-	numberProfileResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	numberProfileResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return numberProfileResponse, nil
+	return r.PostContext(context.Background(), c)
+}
+
+// PostContext :
+//  Executes a post to the numberprofile endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // Put :
@@ -166,31 +149,21 @@ func (r *Request) Post(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Put(c *sa.Client) (*Response, error) {
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPutRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	numberProfileResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, numberProfileResponse); err != nil {
-		return nil, err
-	}
-	numberProfileResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	numberProfileResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return numberProfileResponse, nil
+	return r.PutContext(context.Background(), c)
+}
+
+// PutContext :
+//  Executes a put to the numberprofile endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: should have all the required fields for the put type.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] endpoint: the endpoint for the put request.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PutContext(ctx context.Context, c *sa.Client) (*Response, error) {
+	return sa.Execute[Response](ctx, c, http.MethodPut, endpoint, r)
 }
 
 // EvaluateNumberProfile :
@@ -203,13 +176,7 @@ func (r *Request) Put(c *sa.Client) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) EvaluateNumberProfile(c *sa.Client, userID string, phoneNumber string) (*Response, error) {
-	r.UserID = userID
-	r.PhoneNumber = phoneNumber
-	numberProfileResponse, err := r.Post(c)
-	if err != nil {
-		return nil, err
-	}
-	return numberProfileResponse, nil
+	return NewService(c).EvaluateNumberProfile(context.Background(), userID, phoneNumber)
 }
 
 // UpdateCurrentCarrier :
@@ -226,14 +193,15 @@ func (r *Request) EvaluateNumberProfile(c *sa.Client, userID string, phoneNumber
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) UpdateCurrentCarrier(c *sa.Client, userID string, phoneNumber string, carrierCode string, carrier string, countryCode string, networkType string) (*Response, error) {
-	r.UserID = userID
-	r.PhoneNumber = phoneNumber
-	r.CarrierInfo = CarrierInfo{CarrierCode: carrierCode, Carrier: carrier, CountryCode: countryCode, NetworkType: networkType}
-	numberProfileResponse, err := r.Put(c)
-	if err != nil {
-		return nil, err
-	}
-	return numberProfileResponse, nil
+	carrierInfo := CarrierInfo{CarrierCode: carrierCode, Carrier: carrier, CountryCode: countryCode, NetworkType: networkType}
+	return NewService(c).UpdateCurrentCarrier(context.Background(), userID, phoneNumber, carrierInfo)
+}
+
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
 }
 
 //IsSignatureValid :
@@ -243,24 +211,7 @@ func (r *Request) UpdateCurrentCarrier(c *sa.Client, userID string, phoneNumber
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
@@ -44,7 +44,7 @@ import (
 
 const (
 	uAppID       = "12345"
-	uAppKey      = "12345"
+	uAppKey      = "123456"
 	uHost        = "idp.host.com"
 	uRealm       = "secureauth1"
 	uPort        = 443
@@ -57,6 +57,7 @@ func TestNumberProfile_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	evaluateTest, err := evaluateProfile(client)
 	if err != nil {
@@ -3,7 +3,7 @@ package oath
 import (
 	"testing"
 
-	factors "github.com/jhickmansa/saidp-sdk-go/services/factors"
+	factors "github.com/secureauthcorp/saidp-sdk-go/services/factors"
 	sa "github.com/secureauthcorp/saidp-sdk-go"
 )
 
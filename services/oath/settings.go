@@ -0,0 +1,208 @@
+package oath
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrInvalidSettings : returned when oath settings cannot be parsed or cannot drive a Token.
+var ErrInvalidSettings = errors.New("oath: invalid settings")
+
+// serverTimeLayouts are the server_time formats accepted by ParseSettings, besides unix seconds.
+var serverTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.9999999", time.RFC1123, time.RFC1123Z}
+
+// Settings :
+//	Typed oath settings of a soft token.
+// Fields:
+//	Key: the seed of the token.
+//	Interval: the TOTP time step, a whole number of seconds. 30 seconds when not set.
+//	Length: number of digits of a code, between 6 and 8. 6 when not set.
+//	Offset: time offset configured for the token, applied on top of ClockOffset.
+//	ServerTime: the IdP time when the settings were retrieved, zero when not sent.
+//	ClockOffset: difference between the IdP clock and the local clock when the settings were retrieved.
+//	PinControl: if true, the token must be unlocked with a pin before it generates codes.
+//	FailedWipe: number of failed pin attempts after which the seed is wiped, 0 to never wipe.
+//	ScreenTimeout: idle time after which an unlocked token locks again, 0 to never lock.
+type Settings struct {
+	Key           []byte
+	Interval      time.Duration
+	Length        int
+	Offset        time.Duration
+	ServerTime    time.Time
+	ClockOffset   time.Duration
+	PinControl    bool
+	FailedWipe    int
+	ScreenTimeout time.Duration
+}
+
+// Settings :
+//	Function parsing the oath settings of the response. The clock offset is measured against time.Now().
+// Returns:
+//	Settings: the typed settings.
+//	Error: If an error is encountered, settings will be nil and the error must be handled.
+func (r *Response) Settings() (*Settings, error) {
+	return ParseSettings(r, time.Now())
+}
+
+// ParseSettings :
+//	Helper function parsing the oath settings of a response.
+// Parameters:
+//	[Required] r: response of the oath endpoint. Key is hex (as returned by SecureAuth IdP) or base32 encoded;
+//		   Interval, Offset and ScreenTimeout are in seconds.
+//	[Required] now: local time at which the response was received, used to compute the clock offset.
+// Returns:
+//	Settings: the typed settings.
+//	Error: If an error is encountered, settings will be nil and the error wraps ErrInvalidSettings.
+func ParseSettings(r *Response, now time.Time) (*Settings, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: no response", ErrInvalidSettings)
+	}
+	key, err := parseKey(r.Key)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{Key: key}
+	fields := []struct {
+		name  string
+		value string
+		def   int
+		set   func(int)
+	}{
+		{"interval", r.Interval, 30, func(v int) { s.Interval = time.Duration(v) * time.Second }},
+		{"length", r.Length, 6, func(v int) { s.Length = v }},
+		{"offset", r.Offset, 0, func(v int) { s.Offset = time.Duration(v) * time.Second }},
+		{"failed_wipe", r.FailedWipe, 0, func(v int) { s.FailedWipe = v }},
+		{"screen_timeout", r.ScreenTimeout, 0, func(v int) { s.ScreenTimeout = time.Duration(v) * time.Second }},
+	}
+	for _, f := range fields {
+		v, err := parseInt(f.name, f.value, f.def)
+		if err != nil {
+			return nil, err
+		}
+		f.set(v)
+	}
+	if s.PinControl, err = parseBool("pin_control", r.PinControl); err != nil {
+		return nil, err
+	}
+	if s.ServerTime, err = parseServerTime(r.ServerTime); err != nil {
+		return nil, err
+	}
+	if !s.ServerTime.IsZero() {
+		s.ClockOffset = s.ServerTime.Sub(now)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate :
+//	non-exportable helper checking that the settings can drive a Token.
+func (s *Settings) validate() error {
+	switch {
+	case len(s.Key) == 0:
+		return fmt.Errorf("%w: the key is required", ErrInvalidSettings)
+	case s.Interval < time.Second || s.Interval%time.Second != 0:
+		return fmt.Errorf("%w: the interval must be a positive number of seconds", ErrInvalidSettings)
+	case s.Length < 6 || s.Length > 8:
+		return fmt.Errorf("%w: the length must be between 6 and 8 digits", ErrInvalidSettings)
+	case s.FailedWipe < 0 || s.ScreenTimeout < 0:
+		return fmt.Errorf("%w: failed_wipe and screen_timeout cannot be negative", ErrInvalidSettings)
+	}
+	return nil
+}
+
+// parseKey :
+//	non-exportable helper decoding a hex or base32 seed.
+func parseKey(value string) ([]byte, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if value == "" {
+		return nil, fmt.Errorf("%w: the key is required", ErrInvalidSettings)
+	}
+	if key, err := hex.DecodeString(value); err == nil {
+		return key, nil
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(value, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the key is neither hex nor base32 encoded", ErrInvalidSettings)
+	}
+	return key, nil
+}
+
+// parseInt :
+//	non-exportable helper parsing an integer setting, def is used when the setting is not sent.
+func parseInt(name string, value string, def int) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s %q", ErrInvalidSettings, name, value)
+	}
+	return v, nil
+}
+
+// parseBool :
+//	non-exportable helper parsing a boolean setting, false when not sent.
+func parseBool(name string, value string) (bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s %q", ErrInvalidSettings, name, value)
+	}
+	return v, nil
+}
+
+// parseServerTime :
+//	non-exportable helper parsing the server time, zero when not sent. Times without a zone are taken as UTC.
+func parseServerTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	for _, layout := range serverTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: server_time %q", ErrInvalidSettings, value)
+}
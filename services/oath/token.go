@@ -0,0 +1,358 @@
+package oath
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrTokenLocked : returned when a pin controlled token is asked for a code before Unlock, or after ScreenTimeout.
+var ErrTokenLocked = errors.New("oath: token is locked")
+
+// ErrTokenWiped : returned once the seed was wiped after FailedWipe failed pin attempts.
+var ErrTokenWiped = errors.New("oath: token was wiped")
+
+// ErrInvalidPin : returned by Unlock for a wrong pin.
+var ErrInvalidPin = errors.New("oath: invalid pin")
+
+// Token :
+//	Offline RFC 4226 (HOTP) and RFC 6238 (TOTP) soft token, e.g. a headless authenticator for service accounts
+//	and test automation. TOTP codes follow the IdP clock (Settings.ClockOffset and Settings.Offset), and the
+//	PinControl, FailedWipe and ScreenTimeout policies are enforced. Safe for concurrent use.
+// Fields:
+//	Window: number of steps accepted on each side of the current one by Verify, and ahead of the counter by
+//		   VerifyHOTP. 1 by default.
+//	Hash: HMAC hash function, sha1.New by default.
+//	Now: local clock, time.Now by default.
+type Token struct {
+	Window int
+	Hash   func() hash.Hash
+	Now    func() time.Time
+
+	mu           sync.Mutex
+	settings     Settings
+	pinHash      []byte
+	failures     int
+	unlocked     bool
+	lastActivity time.Time
+	lastStep     int64
+	wiped        bool
+}
+
+// NewToken :
+//	Helper function to create a Token from oath settings.
+// Parameters:
+//	[Required] settings: the settings, e.g. from Response.Settings. The key is copied.
+// Returns:
+//	Token: the token. A pin controlled token must be given a pin with SetPin and unlocked before use.
+//	Error: If an error is encountered, token will be nil and the error wraps ErrInvalidSettings.
+func NewToken(settings Settings) (*Token, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	settings.Key = append([]byte(nil), settings.Key...)
+	return &Token{Window: 1, Hash: sha1.New, Now: time.Now, settings: settings, lastStep: -1}, nil
+}
+
+// Token :
+//	Function creating a Token from the oath settings of the response.
+// Returns:
+//	Token: the token.
+//	Error: If an error is encountered, token will be nil and the error must be handled.
+func (r *Response) Token() (*Token, error) {
+	settings, err := r.Settings()
+	if err != nil {
+		return nil, err
+	}
+	return NewToken(*settings)
+}
+
+// SetPin :
+//	Function setting the pin of a pin controlled token. The token stays locked until Unlock.
+// Parameters:
+//	[Required] pin: the pin.
+// Returns:
+//	Error: If the pin is empty, already set or the token was wiped.
+func (t *Token) SetPin(pin string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.wiped:
+		return ErrTokenWiped
+	case pin == "":
+		return errors.New("A pin is required")
+	case t.pinHash != nil:
+		return errors.New("The pin is already set")
+	}
+	sum := sha256.Sum256([]byte(pin))
+	t.pinHash = sum[:]
+	t.unlocked = false
+	return nil
+}
+
+// Unlock :
+//	Function unlocking a pin controlled token. After FailedWipe consecutive failures the seed is wiped.
+// Parameters:
+//	[Required] pin: the pin given to SetPin.
+// Returns:
+//	Error: ErrInvalidPin for a wrong pin, ErrTokenWiped once the seed was wiped, nil when the token is not
+//		   pin controlled.
+func (t *Token) Unlock(pin string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.wiped {
+		return ErrTokenWiped
+	}
+	if !t.settings.PinControl {
+		return nil
+	}
+	if t.pinHash == nil {
+		return errors.New("A pin must be set with SetPin before unlocking")
+	}
+	sum := sha256.Sum256([]byte(pin))
+	if !hmac.Equal(sum[:], t.pinHash) {
+		t.failures++
+		if t.settings.FailedWipe > 0 && t.failures >= t.settings.FailedWipe {
+			t.wipe()
+			return ErrTokenWiped
+		}
+		return ErrInvalidPin
+	}
+	t.failures = 0
+	t.unlocked = true
+	t.lastActivity = t.Now()
+	return nil
+}
+
+// Lock :
+//	Function locking a pin controlled token until the next Unlock.
+func (t *Token) Lock() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unlocked = false
+}
+
+// Locked :
+//	Function reporting whether the token would refuse to generate a code.
+// Returns:
+//	bool: true when the token is pin controlled and locked (or timed out), or wiped.
+func (t *Token) Locked() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wiped || (t.settings.PinControl && !t.isUnlocked(t.Now()))
+}
+
+// Wiped :
+//	Function reporting whether the seed was wiped after too many failed pin attempts.
+func (t *Token) Wiped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wiped
+}
+
+// HOTP :
+//	Function generating the RFC 4226 code of a counter.
+// Parameters:
+//	[Required] counter: the moving factor.
+// Returns:
+//	string: the code.
+//	Error: ErrTokenLocked or ErrTokenWiped.
+func (t *Token) HOTP(counter uint64) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.access(); err != nil {
+		return "", err
+	}
+	return t.code(counter), nil
+}
+
+// TOTP :
+//	Function generating the RFC 6238 code of the current time step, on the IdP clock.
+// Returns:
+//	string: the code.
+//	Error: ErrTokenLocked or ErrTokenWiped.
+func (t *Token) TOTP() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.access(); err != nil {
+		return "", err
+	}
+	return t.code(uint64(t.step(t.serverTime()))), nil
+}
+
+// TOTPAt :
+//	Function generating the RFC 6238 code of the time step containing at. No clock offset is applied.
+// Parameters:
+//	[Required] at: the time, on the IdP clock.
+// Returns:
+//	string: the code.
+//	Error: ErrTokenLocked or ErrTokenWiped.
+func (t *Token) TOTPAt(at time.Time) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.access(); err != nil {
+		return "", err
+	}
+	return t.code(uint64(t.step(at))), nil
+}
+
+// Verify :
+//	Function verifying a TOTP code against the current time step, Window steps on each side. A code of a step
+//	that was already accepted is rejected, so each code is accepted at most once.
+// Parameters:
+//	[Required] code: the code to verify.
+// Returns:
+//	bool: true when the code is valid.
+//	Error: ErrTokenWiped once the seed was wiped.
+func (t *Token) Verify(code string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.wiped {
+		return false, ErrTokenWiped
+	}
+	current := t.step(t.serverTime())
+	for i := -t.window(); i <= t.window(); i++ {
+		step := current + int64(i)
+		if step < 0 || step <= t.lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(t.code(uint64(step))), []byte(code)) {
+			t.lastStep = step
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// VerifyHOTP :
+//	Function verifying an HOTP code against counter and the Window following counters.
+// Parameters:
+//	[Required] code: the code to verify.
+//	[Required] counter: the next expected counter.
+// Returns:
+//	uint64: the counter to expect next, counter itself when the code is invalid.
+//	bool: true when the code is valid.
+//	Error: ErrTokenWiped once the seed was wiped.
+func (t *Token) VerifyHOTP(code string, counter uint64) (uint64, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.wiped {
+		return counter, false, ErrTokenWiped
+	}
+	for i := uint64(0); i <= uint64(t.window()); i++ {
+		if hmac.Equal([]byte(t.code(counter+i)), []byte(code)) {
+			return counter + i + 1, true, nil
+		}
+	}
+	return counter, false, nil
+}
+
+// access :
+//	non-exportable helper enforcing the wipe and pin policies before a code is generated. Must hold t.mu.
+func (t *Token) access() error {
+	if t.wiped {
+		return ErrTokenWiped
+	}
+	if !t.settings.PinControl {
+		return nil
+	}
+	now := t.Now()
+	if !t.isUnlocked(now) {
+		t.unlocked = false
+		return ErrTokenLocked
+	}
+	t.lastActivity = now
+	return nil
+}
+
+// isUnlocked :
+//	non-exportable helper reporting whether the token is unlocked and within its screen timeout. Must hold t.mu.
+func (t *Token) isUnlocked(now time.Time) bool {
+	if !t.unlocked {
+		return false
+	}
+	return t.settings.ScreenTimeout == 0 || now.Sub(t.lastActivity) < t.settings.ScreenTimeout
+}
+
+// wipe :
+//	non-exportable helper erasing the seed and the pin. Must hold t.mu.
+func (t *Token) wipe() {
+	for i := range t.settings.Key {
+		t.settings.Key[i] = 0
+	}
+	t.settings.Key = nil
+	t.pinHash = nil
+	t.unlocked = false
+	t.wiped = true
+}
+
+// serverTime :
+//	non-exportable helper returning the current time on the IdP clock.
+func (t *Token) serverTime() time.Time {
+	return t.Now().Add(t.settings.ClockOffset + t.settings.Offset)
+}
+
+// step :
+//	non-exportable helper returning the RFC 6238 time step containing at.
+func (t *Token) step(at time.Time) int64 {
+	return at.Unix() / int64(t.settings.Interval/time.Second)
+}
+
+// window :
+//	non-exportable helper returning the verification window, never negative.
+func (t *Token) window() int {
+	if t.Window < 0 {
+		return 0
+	}
+	return t.Window
+}
+
+// code :
+//	non-exportable helper computing the RFC 4226 code of a counter with dynamic truncation.
+func (t *Token) code(counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(t.Hash, t.settings.Key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < t.settings.Length; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", t.settings.Length, value%modulo)
+}
@@ -0,0 +1,187 @@
+package oath
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/secureauthcorp/saidp-sdk-go/saidptest"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// uSeed is the RFC 4226 / RFC 6238 test seed "12345678901234567890", hex encoded.
+const uSeed = "3132333435363738393031323334353637383930"
+
+func newTestToken(t *testing.T, r *Response, now time.Time) *Token {
+	settings, err := ParseSettings(r, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := NewToken(*settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.Now = func() time.Time { return now }
+	return token
+}
+
+// TestTokenVectors_Unit checks the RFC 4226 appendix D and RFC 6238 appendix B (SHA1) test vectors.
+func TestTokenVectors_Unit(t *testing.T) {
+	hotp := newTestToken(t, &Response{Key: uSeed}, time.Now())
+	for counter, want := range []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"} {
+		if code, err := hotp.HOTP(uint64(counter)); err != nil || code != want {
+			t.Errorf("counter %d: expected %s, got %s %v", counter, want, code, err)
+		}
+	}
+	next, ok, err := hotp.VerifyHOTP("969429", 2)
+	if err != nil || !ok || next != 4 {
+		t.Errorf("expected the look ahead window to accept counter 3, got %d %v %v", next, ok, err)
+	}
+	if next, ok, _ = hotp.VerifyHOTP("338314", 2); ok || next != 2 {
+		t.Errorf("expected counter 4 to be outside the window, got %d %v", next, ok)
+	}
+
+	totp := newTestToken(t, &Response{Key: uSeed, Length: "8"}, time.Now())
+	for unix, want := range map[int64]string{59: "94287082", 1111111109: "07081804", 1111111111: "14050471",
+		1234567890: "89005924", 2000000000: "69279037", 20000000000: "65353130"} {
+		if code, err := totp.TOTPAt(time.Unix(unix, 0)); err != nil || code != want {
+			t.Errorf("time %d: expected %s, got %s %v", unix, want, code, err)
+		}
+	}
+}
+
+// TestTokenVerify_Unit checks the clock offset, the verification window and replay protection.
+func TestTokenVerify_Unit(t *testing.T) {
+	local := time.Unix(1111111111, 0)
+	server := local.Add(90 * time.Second)
+	token := newTestToken(t, &Response{Key: uSeed, Interval: "30", ServerTime: server.UTC().Format(time.RFC1123)}, local)
+	code, err := token.TOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := token.TOTPAt(server); code != want {
+		t.Errorf("expected the code of the server time step %s, got %s", want, code)
+	}
+
+	previous, _ := token.TOTPAt(server.Add(-30 * time.Second))
+	tooOld, _ := token.TOTPAt(server.Add(-60 * time.Second))
+	if ok, err := token.Verify(tooOld); ok || err != nil {
+		t.Errorf("expected a code outside the window to be rejected, got %v %v", ok, err)
+	}
+	if ok, err := token.Verify(previous); !ok || err != nil {
+		t.Errorf("expected the previous step to be accepted, got %v %v", ok, err)
+	}
+	if ok, _ := token.Verify(code); !ok {
+		t.Error("expected the current code to be accepted")
+	}
+	if ok, _ := token.Verify(code); ok {
+		t.Error("expected a replayed code to be rejected")
+	}
+	if ok, _ := token.Verify(previous); ok {
+		t.Error("expected a code older than the last accepted one to be rejected")
+	}
+
+	for _, r := range []*Response{{}, {Key: "not a key!"}, {Key: uSeed, Interval: "foo"}, {Key: uSeed, Length: "4"},
+		{Key: uSeed, PinControl: "foo"}, {Key: uSeed, ServerTime: "yesterday"}} {
+		if _, err := ParseSettings(r, local); !errors.Is(err, ErrInvalidSettings) {
+			t.Errorf("expected ErrInvalidSettings for %+v, got %v", r, err)
+		}
+	}
+}
+
+// TestTokenPinPolicy_Unit checks the PinControl, ScreenTimeout and FailedWipe policies.
+func TestTokenPinPolicy_Unit(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	token := newTestToken(t, &Response{Key: uSeed, PinControl: "1", FailedWipe: "3", ScreenTimeout: "60"}, now)
+	token.Now = func() time.Time { return now }
+	if _, err := token.TOTP(); !errors.Is(err, ErrTokenLocked) {
+		t.Errorf("expected ErrTokenLocked before a pin is set, got %v", err)
+	}
+	if err := token.SetPin("1234"); err != nil {
+		t.Fatal(err)
+	}
+	if err := token.Unlock("0000"); !errors.Is(err, ErrInvalidPin) {
+		t.Errorf("expected ErrInvalidPin, got %v", err)
+	}
+	if err := token.Unlock("1234"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := token.TOTP(); err != nil {
+		t.Errorf("expected an unlocked token to generate codes, got %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if _, err := token.TOTP(); err != nil {
+		t.Errorf("expected activity to extend the screen timeout, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := token.HOTP(0); !errors.Is(err, ErrTokenLocked) || !token.Locked() {
+		t.Errorf("expected the token to lock after the screen timeout, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := token.Unlock("0000"); !errors.Is(err, ErrInvalidPin) {
+			t.Errorf("expected ErrInvalidPin, got %v", err)
+		}
+	}
+	if err := token.Unlock("0000"); !errors.Is(err, ErrTokenWiped) || !token.Wiped() {
+		t.Errorf("expected the third failure to wipe the token, got %v", err)
+	}
+	if err := token.Unlock("1234"); !errors.Is(err, ErrTokenWiped) {
+		t.Errorf("expected a wiped token to stay wiped, got %v", err)
+	}
+	if _, err := token.Verify("755224"); !errors.Is(err, ErrTokenWiped) {
+		t.Errorf("expected ErrTokenWiped, got %v", err)
+	}
+}
+
+// TestProvision_Unit provisions a token from the fake IdP and checks it against the RFC 6238 seed.
+func TestProvision_Unit(t *testing.T) {
+	server := saidptest.NewServer()
+	defer server.Close()
+	server.AddUser(saidptest.User{ID: uUser, Password: uPass, OATH: uOtp})
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := NewService(client).Provision(context.Background(), uUser, uPass, uOtp, uId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := token.TOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := token.Verify(code); !ok || err != nil {
+		t.Errorf("expected the generated code to verify, got %v %v", ok, err)
+	}
+	if _, err := NewService(client).Provision(context.Background(), uUser, "wrong", uOtp, uId); err == nil {
+		t.Error("expected invalid credentials to be rejected")
+	}
+}
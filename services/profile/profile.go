@@ -2,13 +2,8 @@ package profile
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
-	"io/ioutil"
 	"net/http"
 
 	sa "github.com/secureauthcorp/saidp-sdk-go"
@@ -142,28 +137,22 @@ type AccessHistories struct {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Get(c *sa.Client, userID string) (*Response, error) {
+	return r.GetContext(context.Background(), c, userID)
+}
+
+// GetContext :
+//	Executes a get request against the users endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: empty struct used to make Get easy.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to perform the get for.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) GetContext(ctx context.Context, c *sa.Client, userID string) (*Response, error) {
 	endpoint := buildEndpointPath(userID)
-	httpRequest, err := c.BuildGetRequest(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	profileResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, profileResponse); err != nil {
-		return nil, err
-	}
-	profileResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	profileResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return profileResponse, nil
+	return sa.Execute[Response](ctx, c, http.MethodGet, endpoint, nil)
 }
 
 // Post :
@@ -176,32 +165,22 @@ func (r *Request) Get(c *sa.Client, userID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Post(c *sa.Client, userID string) (*Response, error) {
+	return r.PostContext(context.Background(), c, userID)
+}
+
+// PostContext :
+//	Executes a post to the users endpoint, bound to ctx.
+// Parameters:
+// 	[Required] r: should have all required fields of the struct populated before using.
+// 	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+// 	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to perform the post for.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PostContext(ctx context.Context, c *sa.Client, userID string) (*Response, error) {
 	endpoint := buildEndpointPath(userID)
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPostRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	profileResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, profileResponse); err != nil {
-		return nil, err
-	}
-	profileResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	profileResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return profileResponse, nil
+	return sa.Execute[Response](ctx, c, http.MethodPost, endpoint, r)
 }
 
 // Put :
@@ -214,32 +193,53 @@ func (r *Request) Post(c *sa.Client, userID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) Put(c *sa.Client, userID string) (*Response, error) {
+	return r.PutContext(context.Background(), c, userID)
+}
+
+// PutContext :
+//	Executes a put to the users endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: should have all the required fields for the put type.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to perform the put for.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) PutContext(ctx context.Context, c *sa.Client, userID string) (*Response, error) {
 	endpoint := buildEndpointPath(userID)
-	jsonRequest, err := json.Marshal(r)
-	if err != nil {
-		return nil, err
-	}
-	httpRequest, err := c.BuildPutRequest(endpoint, string(jsonRequest))
-	if err != nil {
-		return nil, err
-	}
-	httpResponse, err := c.Do(httpRequest)
-	if err != nil {
-		return nil, err
-	}
-	profileResponse := new(Response)
-	body, err := ioutil.ReadAll(httpResponse.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Unmarshal(body, profileResponse); err != nil {
-		return nil, err
+	return sa.Execute[Response](ctx, c, http.MethodPut, endpoint, r)
+}
+
+// Delete :
+//	Executes a delete to the users endpoint, removing the user from the data store.
+// Parameters:
+//	[Required] r: should be empty for this call.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to delete.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) Delete(c *sa.Client, userID string) (*Response, error) {
+	return r.DeleteContext(context.Background(), c, userID)
+}
+
+// DeleteContext :
+//	Executes a delete to the users endpoint, bound to ctx.
+// Parameters:
+//	[Required] r: should be empty for this call.
+//	[Required] ctx: context controlling the lifetime of the request; cancellation and deadlines are propagated.
+//	[Required] c: passing in the client containing authorization and host information.
+//	[Required] userID: the username of the user to delete.
+// Returns:
+//	Response: Struct marshaled from the Json response from the API endpoints.
+//	Error: If an error is encountered, response will be nil and the error must be handled.
+func (r *Request) DeleteContext(ctx context.Context, c *sa.Client, userID string) (*Response, error) {
+	if len(userID) <= 0 {
+		return nil, errors.New("UserId is a required parameter for deleting users")
 	}
-	profileResponse.RawJSON = string(body)
-	httpResponse.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	profileResponse.HTTPResponse = httpResponse
-	httpResponse.Body.Close()
-	return profileResponse, nil
+	endpoint := buildEndpointPath(userID)
+	return sa.Execute[Response](ctx, c, http.MethodDelete, endpoint, nil)
 }
 
 // CreateUser :
@@ -251,17 +251,7 @@ func (r *Request) Put(c *sa.Client, userID string) (*Response, error) {
 //	Response: Struct marshaled from the Json response from the API endpoints.
 //	Error: If an error is encountered, response will be nil and the error must be handled.
 func (r *Request) CreateUser(c *sa.Client) (*Response, error) {
-	if len(r.UserID) <= 0 {
-		return nil, errors.New("UserId is a required parameter for creating new users")
-	}
-	if len(r.Password) <= 0 {
-		return nil, errors.New("UserId is a required parameter for creating new users")
-	}
-	profileResponse, err := r.Post(c, "")
-	if err != nil {
-		return nil, err
-	}
-	return profileResponse, nil
+	return NewService(c).CreateUser(context.Background(), *r)
 }
 
 // buildEndpointPath :
@@ -273,6 +263,13 @@ func buildEndpointPath(userID string) string {
 	return buffer.String()
 }
 
+// SetRaw :
+//	Implements sa.RawReceiver so the shared request pipeline can attach the raw json and the http response.
+func (r *Response) SetRaw(rawJSON string, httpResponse *http.Response) {
+	r.RawJSON = rawJSON
+	r.HTTPResponse = httpResponse
+}
+
 //IsSignatureValid :
 //	Helper function to validate the SecureAuth Response signature in X-SA-SIGNATURE
 // Parameters:
@@ -280,24 +277,7 @@ func buildEndpointPath(userID string) string {
 //	[Required] c: passing in the client with application id and key
 // Returns:
 //	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
-//	error: If an error is encountered, bool will be false and the error must be handled.
+//	error: If the response is not signed, bool will be false and the error must be handled.
 func (r *Response) IsSignatureValid(c *sa.Client) (bool, error) {
-	saDate := r.HTTPResponse.Header.Get("X-SA-DATE")
-	saSignature := r.HTTPResponse.Header.Get("X-SA-SIGNATURE")
-	var buffer bytes.Buffer
-	buffer.WriteString(saDate)
-	buffer.WriteString("\n")
-	buffer.WriteString(c.AppID)
-	buffer.WriteString("\n")
-	buffer.WriteString(r.RawJSON)
-	raw := buffer.String()
-	byteKey, _ := hex.DecodeString(c.AppKey)
-	byteData := []byte(raw)
-	sig := hmac.New(sha256.New, byteKey)
-	sig.Write([]byte(byteData))
-	computedSig := base64.StdEncoding.EncodeToString(sig.Sum(nil))
-	if computedSig != saSignature {
-		return false, nil
-	}
-	return true, nil
+	return c.IsResponseSignatureValid(r.HTTPResponse, r.RawJSON)
 }
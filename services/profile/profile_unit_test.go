@@ -45,7 +45,7 @@ import (
 
 const (
 	uAppID  = "12345"
-	uAppKey = "12345"
+	uAppKey = "123456"
 	uHost   = "idp.host.com"
 	uRealm  = "secureauth1"
 	uPort   = 443
@@ -57,6 +57,7 @@ func TestProfile_Unit(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	gock.InterceptClient(client.HTTPClient())
 
 	getTest, err := getProfile(client)
 	if err != nil {
@@ -82,6 +83,14 @@ func TestProfile_Unit(t *testing.T) {
 		t.Error("Update Profile test failed")
 	}
 
+	deleteTest, err := deleteUser(client)
+	if err != nil {
+		t.Error(err)
+	}
+	if !deleteTest {
+		t.Error("Delete User test failed")
+	}
+
 }
 
 func getProfile(client *sa.Client) (bool, error) {
@@ -217,6 +226,36 @@ func updateProfile(client *sa.Client) (bool, error) {
 	return true, nil
 }
 
+func deleteUser(client *sa.Client) (bool, error) {
+	defer gock.Off()
+
+	deleteJSON := `{"status":"success","message":""}`
+	n := time.Now()
+	headers := map[string]string{
+		"X-SA-DATE":      n.String(),
+		"X-SA-SIGNATURE": makeResponseSignature(client, deleteJSON, n.String()),
+	}
+
+	gock.New("https://idp.host.com:443").
+		Delete("/secureauth1/api/v1/users/" + uUser).
+		Reply(200).BodyString(deleteJSON).
+		SetHeaders(headers)
+
+	profileRequest := new(Request)
+	profileResponse, err := profileRequest.Delete(client, uUser)
+	if err != nil {
+		return false, err
+	}
+	valid, err := profileResponse.IsSignatureValid(client)
+	if err != nil {
+		return false, err
+	}
+	if !valid {
+		return false, errors.New("Response signature is invalid")
+	}
+	return true, nil
+}
+
 func makeResponseSignature(c *sa.Client, r string, t string) string {
 	var buffer bytes.Buffer
 	buffer.WriteString(t)
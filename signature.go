@@ -0,0 +1,160 @@
+package saidp_sdk_go
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+var (
+	hdrSASignatureKey = http.CanonicalHeaderKey("X-SA-Signature")
+	// saDateLayouts are the layouts accepted for X-SA-Date when enforcing the maximum skew.
+	saDateLayouts = []string{time.RFC1123, time.RFC1123Z, time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"}
+)
+
+// ErrInvalidSignature : returned when the X-SA-Signature of a response is missing, does not match the body or is too old.
+var ErrInvalidSignature = errors.New("saidp: invalid response signature")
+
+// WithSignatureVerification :
+//	Option to verify X-SA-Signature on every 200 response inside Do/DoContext. A response that is not signed,
+//	whose signature does not match, or whose X-SA-Date is further than maxSkew from the local clock is rejected
+//	with an error wrapping ErrInvalidSignature.
+// Parameters:
+//	[Required] maxSkew: maximum difference allowed between X-SA-Date and the local clock; zero disables the check.
+func WithSignatureVerification(maxSkew time.Duration) ClientOption {
+	return func(c *Client) error {
+		if maxSkew < 0 {
+			return errors.New("Max skew must not be negative")
+		}
+		c.verifySignatures = true
+		c.maxSkew = maxSkew
+		return nil
+	}
+}
+
+// VerifyResponseSignature :
+//	Function to verify the SecureAuth signature of a response using a constant time comparison against every
+//	active AppKey, so responses signed with the previous key are accepted during a rotation. The X-SA-Date
+//	skew is enforced when the client was built with WithSignatureVerification and a non zero skew.
+// Parameters:
+//	[Required] header: the http response headers holding X-SA-Date and X-SA-Signature.
+//	[Required] body: the raw response body.
+// Returns:
+//	Error: nil when the signature is valid, otherwise an error wrapping ErrInvalidSignature.
+func (c Client) VerifyResponseSignature(header http.Header, body []byte) error {
+	saDate := header.Get(hdrSADateKey)
+	saSignature := header.Get(hdrSASignatureKey)
+	if saDate == "" || saSignature == "" {
+		return fmt.Errorf("%w: X-SA-Date and X-SA-Signature headers are required", ErrInvalidSignature)
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString(saDate)
+	buffer.WriteString("\n")
+	buffer.WriteString(c.AppID)
+	buffer.WriteString("\n")
+	buffer.Write(body)
+	matched := false
+	for _, key := range c.ActiveKeys() {
+		computedSig, err := makeHmac(buffer.String(), key)
+		if err != nil {
+			return err
+		}
+		if hmac.Equal([]byte(computedSig), []byte(saSignature)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+	if c.maxSkew > 0 {
+		signedAt, err := parseSADate(saDate)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+		skew := c.now().Sub(signedAt)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > c.maxSkew {
+			return fmt.Errorf("%w: X-SA-Date is %v away from the local clock", ErrInvalidSignature, skew)
+		}
+	}
+	return nil
+}
+
+// IsResponseSignatureValid :
+//	Helper function backing the IsSignatureValid function of every service Response.
+// Parameters:
+//	[Required] resp: the http response returned with the service Response.
+//	[Required] rawJSON: the raw json body of the service Response.
+// Returns:
+//	bool: if true, computed signature matches X-SA-SIGNATURE. if false, computed signature does not match.
+//	error: If the response is missing or not signed, bool will be false and the error must be handled.
+func (c Client) IsResponseSignatureValid(resp *http.Response, rawJSON string) (bool, error) {
+	if resp == nil {
+		return false, errors.New("A http response is required to validate the signature")
+	}
+	err := c.VerifyResponseSignature(resp.Header, []byte(rawJSON))
+	if err == nil {
+		return true, nil
+	}
+	if resp.Header.Get(hdrSADateKey) == "" || resp.Header.Get(hdrSASignatureKey) == "" {
+		return false, err
+	}
+	return false, nil
+}
+
+// verifyResponse :
+//	non-exportable helper used by DoContext to verify a response signature. The body is buffered and put back
+//	so callers can read it as usual.
+func (c Client) verifyResponse(resp *http.Response) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return c.VerifyResponseSignature(resp.Header, body)
+}
+
+// parseSADate :
+//	non-exportable helper to parse the X-SA-Date header.
+func parseSADate(value string) (time.Time, error) {
+	for _, layout := range saDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse X-SA-Date %q", value)
+}
@@ -0,0 +1,83 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestSignatureVerification_Unit(t *testing.T) {
+	body := `{"status":"found","message":""}`
+	var mode string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date := getGMTTimestamp(time.Now())
+		if mode == "stale" {
+			date = strings.Replace(time.Now().Add(-time.Hour).UTC().Format(time.RFC1123), "UTC", "GMT", 1)
+		}
+		sig, _ := makeHmac(date+"\n"+uAppID+"\n"+body, uAppKey)
+		switch mode {
+		case "tampered":
+			sig, _ = makeHmac(date+"\n"+uAppID+"\n"+`{"status":"invalid"}`, uAppKey)
+		case "missing":
+			sig = ""
+		}
+		w.Header().Set("X-SA-Date", date)
+		w.Header().Set("X-SA-Signature", sig)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server, WithSignatureVerification(5*time.Minute))
+
+	for _, m := range []string{"valid", "tampered", "missing", "stale"} {
+		mode = m
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if m == "valid" {
+			if err != nil {
+				t.Fatalf("expected a valid signature, got %v", err)
+			}
+			raw, _ := ioutil.ReadAll(resp.Body)
+			if string(raw) != body {
+				t.Errorf("expected the verified body to remain readable, got %q", raw)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", m, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package saidp_sdk_go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrCertificatePin : returned by the TLS handshake when no certificate of the verified chain matches a pinned SPKI hash.
+var ErrCertificatePin = errors.New("saidp: server certificate does not match any pinned public key")
+
+// TLSOptions :
+//	TLS settings for the connections made to the SecureAuth web service. Certificate validation is always
+//	performed; use RootCAs for a private PKI instead of bypassing validation.
+// Fields:
+//	MinVersion: lowest TLS version accepted. Defaults to TLS 1.2.
+//	MaxVersion: highest TLS version offered. Defaults to the highest version supported (TLS 1.3).
+//	CipherSuites: TLS 1.2 cipher suites. TLS 1.3 suites are not configurable. Defaults to Go's secure defaults.
+//	RootCAs: pool used to verify the server certificate. Defaults to the system roots.
+//	ServerName: overrides the name verified against the server certificate.
+//	Certificates: client certificates presented for mutual TLS.
+//	PinnedSPKIHashes: base64 encoded SHA-256 hashes of SubjectPublicKeyInfo (see SPKIHash). When set, at least one
+//		   certificate of the verified chain must match one of them.
+type TLSOptions struct {
+	MinVersion       uint16
+	MaxVersion       uint16
+	CipherSuites     []uint16
+	RootCAs          *x509.CertPool
+	ServerName       string
+	Certificates     []tls.Certificate
+	PinnedSPKIHashes []string
+}
+
+// WithTLSOptions :
+//	Option to configure TLS for the transport of the client. It applies to a copy of the transport set up by the
+//	options before it (e.g. WithHTTPClient, WithTransport or WithTimeout), keeping their proxy, pooling and
+//	timeout settings; options after it that replace the http client replace the TLS settings as well.
+//	Cannot be combined with BypassCertValidation or with a round tripper that is not an *http.Transport.
+// Parameters:
+//	[Required] opts: the TLS settings to apply.
+func WithTLSOptions(opts TLSOptions) ClientOption {
+	return func(c *Client) error {
+		if c.BypassCertValidation {
+			return errors.New("TLS options cannot be combined with bypassing certificate validation")
+		}
+		config, err := opts.Config()
+		if err != nil {
+			return err
+		}
+		hc := *c.HTTPClient()
+		var transport *http.Transport
+		switch rt := hc.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = rt.Clone()
+		default:
+			return errors.New("TLS options require an *http.Transport, configure TLS on the supplied round tripper instead")
+		}
+		transport.TLSClientConfig = config
+		hc.Transport = transport
+		c.httpClient = &hc
+		return nil
+	}
+}
+
+// Config :
+//	Function building the tls.Config described by the options.
+// Returns:
+//	tls.Config: config ready to be used by an http.Transport.
+//	Error: If the options are inconsistent, config will be nil and the error must be handled.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:   o.MinVersion,
+		MaxVersion:   o.MaxVersion,
+		CipherSuites: o.CipherSuites,
+		RootCAs:      o.RootCAs,
+		ServerName:   o.ServerName,
+		Certificates: o.Certificates,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if config.MinVersion < tls.VersionTLS12 {
+		return nil, errors.New("TLS versions below 1.2 are not supported")
+	}
+	if config.MaxVersion != 0 && config.MaxVersion < config.MinVersion {
+		return nil, errors.New("TLS max version must not be lower than min version")
+	}
+	if len(o.PinnedSPKIHashes) > 0 {
+		pins := make([][]byte, 0, len(o.PinnedSPKIHashes))
+		for _, pin := range o.PinnedSPKIHashes {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != sha256.Size {
+				return nil, errors.New("Pinned SPKI hashes must be base64 encoded SHA-256 digests")
+			}
+			pins = append(pins, hash)
+		}
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			for _, chain := range verifiedChains {
+				for _, cert := range chain {
+					sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+					for _, pin := range pins {
+						if bytes.Equal(sum[:], pin) {
+							return nil
+						}
+					}
+				}
+			}
+			return ErrCertificatePin
+		}
+	}
+	return config, nil
+}
+
+// SPKIHash :
+//	Helper function computing the pin of a certificate for TLSOptions.PinnedSPKIHashes.
+// Parameters:
+//	[Required] cert: the certificate to pin, usually the server leaf or an intermediate CA.
+// Returns:
+//	string: base64 encoded SHA-256 hash of the certificate SubjectPublicKeyInfo.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// LoadCertPool :
+//	Helper function loading PEM encoded CA certificates, e.g. the roots of a private PKI, into a pool for TLSOptions.RootCAs.
+// Parameters:
+//	[Required] paths: files holding one or more PEM encoded certificates.
+// Returns:
+//	x509.CertPool: pool holding every certificate found.
+//	Error: If a file cannot be read or holds no certificate, pool will be nil and the error must be handled.
+func LoadCertPool(paths ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, path := range paths {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("No PEM encoded certificate found in " + path)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,122 @@
+package saidp_sdk_go
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestTLSOptions_Unit(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS.Version != tls.VersionTLS13 {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			return
+		}
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":"found","message":""}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	do := func(opts TLSOptions) error {
+		client := newTestClient(t, server, WithTLSOptions(opts))
+		req, err := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	clientCerts := server.TLS.Certificates
+	if err := do(TLSOptions{RootCAs: roots, Certificates: clientCerts, PinnedSPKIHashes: []string{SPKIHash(server.Certificate())}}); err != nil {
+		t.Errorf("expected a TLS 1.3 mutual TLS call with a matching pin to succeed, got %v", err)
+	}
+	if err := do(TLSOptions{RootCAs: roots, Certificates: clientCerts, PinnedSPKIHashes: []string{base64.StdEncoding.EncodeToString(make([]byte, 32))}}); !errors.Is(err, ErrCertificatePin) {
+		t.Errorf("expected ErrCertificatePin, got %v", err)
+	}
+	if err := do(TLSOptions{Certificates: clientCerts}); err == nil {
+		t.Error("expected the test server certificate to be rejected without the custom root pool")
+	}
+	if _, err := NewClientWithOptions(uAppID, uAppKey, "idp.host.com", 443, uRealm, true, true, WithTLSOptions(TLSOptions{})); err == nil {
+		t.Error("expected TLS options to be rejected together with bypassing certificate validation")
+	}
+}
+
+func TestTLSOptionsKeepHTTPClient_Unit(t *testing.T) {
+	supplied := &http.Transport{MaxIdleConnsPerHost: 7}
+	hc := &http.Client{Transport: supplied, Timeout: 3 * time.Second}
+	client, err := NewClientWithOptions(uAppID, uAppKey, "idp.host.com", 443, uRealm, true, false,
+		WithHTTPClient(hc), WithTLSOptions(TLSOptions{MinVersion: tls.VersionTLS13}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := client.HTTPClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected an *http.Transport, got %T", client.HTTPClient().Transport)
+	}
+	if transport.MaxIdleConnsPerHost != 7 || client.HTTPClient().Timeout != 3*time.Second {
+		t.Error("expected the supplied http client settings to be kept")
+	}
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Error("expected the TLS options to be applied")
+	}
+	if supplied.TLSClientConfig != nil && supplied.TLSClientConfig.MinVersion == tls.VersionTLS13 {
+		t.Error("expected the supplied transport to be left untouched")
+	}
+
+	client, err = NewClientWithOptions(uAppID, uAppKey, "idp.host.com", 443, uRealm, true, false,
+		WithTimeout(time.Second), WithTLSOptions(TLSOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.HTTPClient().Timeout != time.Second {
+		t.Errorf("expected the timeout to be kept, got %v", client.HTTPClient().Timeout)
+	}
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) { return nil, errors.New("unused") })
+	if _, err := NewClientWithOptions(uAppID, uAppKey, "idp.host.com", 443, uRealm, true, false,
+		WithTransport(rt), WithTLSOptions(TLSOptions{})); err == nil {
+		t.Error("expected an error for TLS options on a custom round tripper")
+	}
+}
@@ -0,0 +1,138 @@
+package saidp_sdk_go
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+var (
+	defaultClientsOnce  sync.Once
+	defaultSecureClient *http.Client
+	defaultBypassClient *http.Client
+)
+
+// ClientOption :
+//	Functional option used by NewClientWithOptions to configure a Client beyond the required parameters.
+type ClientOption func(*Client) error
+
+// WithHTTPClient :
+//	Option to supply the http.Client used for every call, e.g. one configured for proxies, mTLS or custom timeouts.
+//	When set, TLS settings derived from BypassCertValidation are not applied; the supplied client is used as is.
+//	Place WithTLSOptions after it to apply TLS settings to a copy of its transport.
+// Parameters:
+//	[Required] hc: the http client to use.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("A non-nil http client is required")
+		}
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithTransport :
+//	Option to supply the http.RoundTripper used for every call while keeping the rest of the default http.Client.
+// Parameters:
+//	[Required] rt: the round tripper (usually an *http.Transport) to use.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) error {
+		if rt == nil {
+			return errors.New("A non-nil transport is required")
+		}
+		c.httpClient = &http.Client{Transport: rt, Timeout: c.httpClient.Timeout}
+		return nil
+	}
+}
+
+// WithTimeout :
+//	Option to bound every call (connection, request and reading the response body) by a fixed timeout.
+//	Per call deadlines can still be set through the Context variants.
+// Parameters:
+//	[Required] timeout: the overall timeout for a single call; zero means no timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return errors.New("Timeout must not be negative")
+		}
+		hc := *c.httpClient
+		hc.Timeout = timeout
+		c.httpClient = &hc
+		return nil
+	}
+}
+
+// HTTPClient :
+//	Function returning the http.Client the Client executes requests with. Clients built by NewClient share
+//	a single pooled transport for their whole lifetime; Clients declared as struct literals fall back to a
+//	package wide pooled client matching their BypassCertValidation setting.
+// Returns:
+//	http.Client: the http client used by Do and DoContext.
+func (c Client) HTTPClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	defaultClientsOnce.Do(func() {
+		defaultSecureClient = &http.Client{Transport: newTransport(false)}
+		defaultBypassClient = &http.Client{Transport: newTransport(true)}
+	})
+	if c.BypassCertValidation {
+		return defaultBypassClient
+	}
+	return defaultSecureClient
+}
+
+// newTransport :
+//	non-exportable helper to build a pooled transport based on http.DefaultTransport (proxy from environment,
+//	keep-alives, idle connection limits) with the TLS settings for the bypass flag applied.
+func newTransport(bypassCert bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 32
+	if bypassCert {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		return transport
+	}
+	// If the bypass flag is not set, consider us in a "production" environment: certificates are
+	// validated, TLS 1.2 is the floor and TLS 1.3 is negotiated whenever the server supports it.
+	config, _ := TLSOptions{
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+		},
+	}.Config()
+	config.CurvePreferences = []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256}
+	transport.TLSClientConfig = config
+	return transport
+}
@@ -2,6 +2,7 @@ package validators
 
 import "net/http"
 import "fmt"
+import "encoding/hex"
 
 /*
 **********************************************************************
@@ -31,8 +32,9 @@ import "fmt"
  */
 
 var (
-	allowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut}
+	allowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch}
 	typeList       = []string{"call", "sms", "email"}
+	authTypeList   = []string{"user_id", "password", "kba", "oath", "pin", "call", "sms", "email", "push", "push_accept", "help_desk"}
 )
 
 // ValidateHTTPMethod :
@@ -57,6 +59,27 @@ func ValidateRequestType(str string) bool {
 	return false
 }
 
+// ValidateAuthType :
+//	exportable helper to validate the type of an auth endpoint request.
+func ValidateAuthType(str string) bool {
+	for _, v := range authTypeList {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAppKey :
+//	exportable helper to validate that an AppKey is a non empty hex encoded string.
+func ValidateAppKey(str string) bool {
+	if isNil(str) {
+		return false
+	}
+	_, err := hex.DecodeString(str)
+	return err == nil
+}
+
 // ValidateClientParams :
 // exportable helper to validate expected client parameters for calling NewClient()
 func ValidateClientParams(params map[string]string) (bool, error) {
@@ -0,0 +1,180 @@
+package saidp_sdk_go
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/secureauthcorp/saidp-sdk-go/utilities/validators"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+// ErrInvalidRequestSignature : returned by VerifyRequest when the Authorization header of an inbound request is
+// missing, malformed, signed by an unknown application, does not match the request or is too old.
+var ErrInvalidRequestSignature = errors.New("saidp: invalid request signature")
+
+// MaxVerifiedBodySize : largest request body, in bytes, read by VerifyRequest. Larger bodies are rejected with
+// ErrInvalidRequestSignature before any HMAC is computed.
+const MaxVerifiedBodySize = 1 << 20
+
+// KeyLookup :
+//	Function resolving the hex encoded application key of an application id, used by VerifyRequest.
+type KeyLookup func(appID string) (string, error)
+
+// VerifyRequest :
+//	Function verifying an inbound request signed the way Client.Sign signs outgoing ones: a
+//	"Basic base64(appID:hmac)" Authorization header where hmac is computed over the method, X-SA-Date (or Date),
+//	application id, path with its query and body. The comparison is constant time. The body, up to
+//	MaxVerifiedBodySize bytes, is buffered and put back so handlers can still read it. The request date is checked
+//	against time.Now.
+// Parameters:
+//	[Required] r: the inbound request.
+//	[Required] keyLookup: resolves the application key of the application id found in the header.
+//	[Required] maxSkew: maximum difference allowed between the request date and the local clock; zero disables the check.
+// Returns:
+//	string: the application id that signed the request.
+//	Error: nil when the request is authentic, otherwise an error wrapping ErrInvalidRequestSignature.
+func VerifyRequest(r *http.Request, keyLookup KeyLookup, maxSkew time.Duration) (string, error) {
+	return VerifyRequestWithClock(r, keyLookup, maxSkew, time.Now)
+}
+
+// VerifyRequest :
+//	Function verifying an inbound request like the package level VerifyRequest, measuring the skew of the request
+//	date against the client clock (see WithClock).
+// Parameters:
+//	[Required] r: the inbound request.
+//	[Required] keyLookup: resolves the application key of the application id found in the header.
+//	[Required] maxSkew: maximum difference allowed between the request date and the client clock; zero disables the check.
+// Returns:
+//	string: the application id that signed the request.
+//	Error: nil when the request is authentic, otherwise an error wrapping ErrInvalidRequestSignature.
+func (c Client) VerifyRequest(r *http.Request, keyLookup KeyLookup, maxSkew time.Duration) (string, error) {
+	return VerifyRequestWithClock(r, keyLookup, maxSkew, c.now)
+}
+
+// VerifyRequestWithClock :
+//	Function verifying an inbound request like VerifyRequest, measuring the skew of the request date against now
+//	instead of time.Now, e.g. to verify requests signed with a frozen clock in tests.
+// Parameters:
+//	[Required] r: the inbound request.
+//	[Required] keyLookup: resolves the application key of the application id found in the header.
+//	[Required] maxSkew: maximum difference allowed between the request date and now; zero disables the check.
+//	[Required] now: function returning the current time.
+// Returns:
+//	string: the application id that signed the request.
+//	Error: nil when the request is authentic, otherwise an error wrapping ErrInvalidRequestSignature.
+func VerifyRequestWithClock(r *http.Request, keyLookup KeyLookup, maxSkew time.Duration, now func() time.Time) (string, error) {
+	if keyLookup == nil {
+		return "", errors.New("A key lookup function is required")
+	}
+	if now == nil {
+		return "", errors.New("A non-nil clock is required")
+	}
+	if !validators.ValidateHTTPMethod(r.Method) {
+		return "", fmt.Errorf("%w: method %s is not supported", ErrInvalidRequestSignature, r.Method)
+	}
+	appID, signature, err := parseAuthorization(r.Header.Get(hdrAuthorizationKey))
+	if err != nil {
+		return "", err
+	}
+	timestamp := r.Header.Get(hdrSADateKey)
+	if timestamp == "" {
+		timestamp = r.Header.Get(hdrDateKey)
+	}
+	if timestamp == "" {
+		return "", fmt.Errorf("%w: X-SA-Date or Date header is required", ErrInvalidRequestSignature)
+	}
+	if maxSkew > 0 {
+		signedAt, err := parseSADate(timestamp)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidRequestSignature, err)
+		}
+		skew := now().Sub(signedAt)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew {
+			return "", fmt.Errorf("%w: request date is %v away from the local clock", ErrInvalidRequestSignature, skew)
+		}
+	}
+	appKey, err := keyLookup(appID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequestSignature, err)
+	}
+	content := ""
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxVerifiedBodySize+1))
+		r.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		if len(body) > MaxVerifiedBodySize {
+			return "", fmt.Errorf("%w: body is larger than %d bytes", ErrInvalidRequestSignature, MaxVerifiedBodySize)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		content = string(body)
+	}
+	realm, endpoint := splitRealm(r.URL.EscapedPath())
+	if r.URL.RawQuery != "" {
+		endpoint += "?" + r.URL.RawQuery
+	}
+	payload := buildAuthPayload(r.Method, timestamp, appID, realm, endpoint, content)
+	computedSig, err := makeHmac(payload, appKey)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal([]byte(computedSig), []byte(signature)) {
+		return "", fmt.Errorf("%w: signature does not match", ErrInvalidRequestSignature)
+	}
+	return appID, nil
+}
+
+// parseAuthorization :
+//	non-exportable helper splitting a "Basic base64(appID:hmac)" Authorization header.
+func parseAuthorization(header string) (string, string, error) {
+	if !strings.HasPrefix(header, "Basic ") {
+		return "", "", fmt.Errorf("%w: Basic Authorization header is required", ErrInvalidRequestSignature)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: Authorization header is not base64", ErrInvalidRequestSignature)
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%w: Authorization header must hold appID:signature", ErrInvalidRequestSignature)
+	}
+	return parts[0], parts[1], nil
+}
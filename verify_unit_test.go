@@ -0,0 +1,128 @@
+package saidp_sdk_go
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+**********************************************************************
+*   @author jhickman@secureauth.com
+*
+*  Copyright (c) 2017, SecureAuth
+*  All rights reserved.
+*
+*    Redistribution and use in source and binary forms, with or without modification,
+*    are permitted provided that the following conditions are met:
+*
+*    1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+*
+*    2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer
+*    in the documentation and/or other materials provided with the distribution.
+*
+*    3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived
+*    from this software without specific prior written permission.
+*
+*    THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO,
+*    THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+*    CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+*    PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+*    LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE,
+*    EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+**********************************************************************
+ */
+
+func TestVerifyRequest_Unit(t *testing.T) {
+	client, err := NewClient(uAppID, uAppKey, "localhost", 443, uRealm, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(appID string) (string, error) {
+		if appID != uAppID {
+			return "", errors.New("unknown app id")
+		}
+		return uAppKey, nil
+	}
+	get, _ := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	if appID, err := VerifyRequest(get, lookup, time.Minute); err != nil || appID != uAppID {
+		t.Errorf("expected a valid GET request, got %q %v", appID, err)
+	}
+	content := `{"user_id":"` + uUser + `","type":"user_id"}`
+	post, _ := client.BuildPostRequest("/api/v1/auth", content)
+	if _, err := VerifyRequest(post, lookup, 0); err != nil {
+		t.Errorf("expected a valid POST request, got %v", err)
+	}
+	if body, _ := ioutil.ReadAll(post.Body); string(body) != content {
+		t.Error("expected the body to remain readable after verification")
+	}
+
+	tampered, _ := client.BuildPostRequest("/api/v1/auth", content)
+	tampered.Body = ioutil.NopCloser(strings.NewReader(`{"user_id":"admin","type":"user_id"}`))
+	other := *client
+	other.AppKey = "654321"
+	wrongKey, _ := other.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	other.AppID = "99999"
+	unknownApp, _ := other.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	stale, _ := client.BuildGetRequest("/api/v1/users/" + uUser + "/factors")
+	stale.Header.Set(hdrSADateKey, time.Now().Add(-time.Hour).UTC().Format(time.RFC1123))
+	unsigned, _ := http.NewRequest(http.MethodGet, get.URL.String(), nil)
+	for name, req := range map[string]*http.Request{
+		"tampered": tampered, "wrong key": wrongKey, "unknown app": unknownApp, "stale": stale, "unsigned": unsigned,
+	} {
+		if _, err := VerifyRequest(req, lookup, time.Minute); !errors.Is(err, ErrInvalidRequestSignature) {
+			t.Errorf("%s: expected ErrInvalidRequestSignature, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyRequestClock_Unit(t *testing.T) {
+	fixed := time.Date(2020, time.January, 2, 15, 4, 5, 0, time.UTC)
+	client, err := NewClientWithOptions(uAppID, uAppKey, "localhost", 443, uRealm, true, false,
+		WithClock(func() time.Time { return fixed }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(string) (string, error) { return uAppKey, nil }
+	req, _ := client.BuildGetRequest("/api/v1/users/" + uUser + "/throttle?count=1")
+	if _, err := VerifyRequest(req, lookup, time.Minute); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected a request signed in 2020 to be stale against time.Now, got %v", err)
+	}
+	if _, err := client.VerifyRequest(req, lookup, time.Minute); err != nil {
+		t.Errorf("expected the client clock to be used, got %v", err)
+	}
+	later := func() time.Time { return fixed.Add(2 * time.Minute) }
+	if _, err := VerifyRequestWithClock(req, lookup, time.Minute, later); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected the supplied clock to be used, got %v", err)
+	}
+	req.URL.RawQuery = "count=2"
+	if _, err := client.VerifyRequest(req, lookup, time.Minute); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected a tampered query to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyRequestBodyLimit_Unit(t *testing.T) {
+	client, err := NewClient(uAppID, uAppKey, "localhost", 443, uRealm, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(string) (string, error) { return uAppKey, nil }
+	content := `{"user_id":"` + strings.Repeat("a", MaxVerifiedBodySize) + `"}`
+	req, err := client.BuildPostRequest("/api/v1/auth", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyRequest(req, lookup, 0); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected a body over MaxVerifiedBodySize to be rejected, got %v", err)
+	}
+	req, err = client.BuildPostRequest("/api/v1/auth", `{"user_id":"`+uUser+`"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyRequest(req, lookup, 0); err != nil {
+		t.Errorf("expected a small body to verify, got %v", err)
+	}
+}